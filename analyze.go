@@ -0,0 +1,126 @@
+package pathparsing
+
+import "fmt"
+
+// SuggestionKind classifies an improvement reported by Analyze.
+type SuggestionKind int
+
+const (
+	// SuggestHorizontalVertical is a line that is horizontal or vertical and
+	// could be written more briefly as an H or V command.
+	SuggestHorizontalVertical SuggestionKind = iota
+	// SuggestRedundantMoveTo is a moveTo immediately followed by another
+	// moveTo, so it draws nothing and can be dropped.
+	SuggestRedundantMoveTo
+	// SuggestClose is an open subpath ending where it starts, whose last
+	// segment could be replaced by a close (see SuggestCloses).
+	SuggestClose
+	// SuggestMergeCollinear is a line continuing the previous line in the
+	// same direction, which could be merged into it (see MergeCollinear).
+	SuggestMergeCollinear
+)
+
+// analyzeTolerance is the distance and angle below which Analyze considers
+// points to coincide and directions to be the same.
+const analyzeTolerance = 1e-9
+
+// Suggestion is a way to simplify SVG path data found by Analyze.
+type Suggestion struct {
+	// Segment is the index of the parsed segment the suggestion applies to.
+	Segment int
+	Kind    SuggestionKind
+	Message string
+}
+
+// String returns a string representation of the Suggestion.
+func (s Suggestion) String() string {
+	return fmt.Sprintf("%s at segment %d", s.Message, s.Segment)
+}
+
+// AnalysisReport lists the suggestions Analyze found, ordered by segment.
+type AnalysisReport struct {
+	Suggestions []Suggestion
+}
+
+// Analyze parses SVG path data and reports how it could be written more
+// compactly without changing its geometry: lines that could use H or V,
+// moveTos that draw nothing, subpaths that could be closed and lines that
+// could be merged. Segments are numbered as returned by ParsePath.
+func Analyze(svg string) (AnalysisReport, error) {
+	segments, err := ParsePath(svg)
+	if err != nil {
+		return AnalysisReport{}, err
+	}
+
+	var report AnalysisReport
+	closable := make(map[int]bool)
+	lastSegments := subpathLastSegments(segments)
+	for _, index := range SuggestCloses(segments, analyzeTolerance) {
+		closable[lastSegments[index]] = true
+	}
+
+	absolute := AbsoluteSegments(segments)
+	var current, subpathStart PathOffset
+	for i, seg := range segments {
+		abs := absolute[i]
+		switch {
+		case seg.Command == SvgPathSegTypeMoveToAbs || seg.Command == SvgPathSegTypeMoveToRel:
+			if i+1 < len(segments) && (segments[i+1].Command == SvgPathSegTypeMoveToAbs || segments[i+1].Command == SvgPathSegTypeMoveToRel) {
+				report.add(i, SuggestRedundantMoveTo, "moveTo is followed by another moveTo")
+			}
+		case seg.Command == SvgPathSegTypeLineToAbs || seg.Command == SvgPathSegTypeLineToRel:
+			if abs.TargetPoint.Dy == current.Dy && abs.TargetPoint.Dx != current.Dx {
+				report.add(i, SuggestHorizontalVertical, "horizontal line could use H")
+			} else if abs.TargetPoint.Dx == current.Dx && abs.TargetPoint.Dy != current.Dy {
+				report.add(i, SuggestHorizontalVertical, "vertical line could use V")
+			}
+		}
+		if i > 0 && abs.Command == SvgPathSegTypeLineToAbs && absolute[i-1].Command == SvgPathSegTypeLineToAbs {
+			previousStart := subpathStart
+			if i > 1 && absolute[i-2].Command != SvgPathSegTypeClose {
+				previousStart = absolute[i-2].TargetPoint
+			}
+			incoming := current.Subtract(previousStart)
+			outgoing := abs.TargetPoint.Subtract(current)
+			if incoming != ZeroPathOffset() && outgoing != ZeroPathOffset() &&
+				angleBetween(incoming.Direction(), outgoing.Direction()) <= analyzeTolerance {
+				report.add(i, SuggestMergeCollinear, "line continues the previous line and could be merged into it")
+			}
+		}
+		if closable[i] {
+			report.add(i, SuggestClose, "subpath ends at its start and could be closed")
+		}
+
+		switch abs.Command {
+		case SvgPathSegTypeMoveToAbs:
+			subpathStart = abs.TargetPoint
+			current = abs.TargetPoint
+		case SvgPathSegTypeClose:
+			current = subpathStart
+		default:
+			current = abs.TargetPoint
+		}
+	}
+	return report, nil
+}
+
+// add appends a suggestion to the report.
+func (r *AnalysisReport) add(segment int, kind SuggestionKind, message string) {
+	r.Suggestions = append(r.Suggestions, Suggestion{segment, kind, message})
+}
+
+// subpathLastSegments returns, for each subpath as numbered by
+// SplitSubpaths, the index of its last segment.
+func subpathLastSegments(segments []PathSegmentData) []int {
+	var last []int
+	closed := false
+	for i, seg := range segments {
+		isMoveTo := seg.Command == SvgPathSegTypeMoveToAbs || seg.Command == SvgPathSegTypeMoveToRel
+		if isMoveTo || closed || len(last) == 0 {
+			last = append(last, i)
+		}
+		last[len(last)-1] = i
+		closed = seg.Command == SvgPathSegTypeClose
+	}
+	return last
+}
@@ -0,0 +1,39 @@
+package pathparsing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	report, err := Analyze("M0,0 M5,5 L10,5 l5,0 L15,10 L5,5 M20,20 h1 Z l1,1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type found struct {
+		segment int
+		kind    SuggestionKind
+	}
+	var actual []found
+	for _, s := range report.Suggestions {
+		actual = append(actual, found{s.Segment, s.Kind})
+	}
+	expected := []found{
+		{0, SuggestRedundantMoveTo},
+		{2, SuggestHorizontalVertical},
+		{3, SuggestHorizontalVertical},
+		{3, SuggestMergeCollinear},
+		{4, SuggestHorizontalVertical},
+		{5, SuggestClose},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if s := report.Suggestions[0].String(); s != "moveTo is followed by another moveTo at segment 0" {
+		t.Errorf("unexpected string %q", s)
+	}
+
+	if _, err := Analyze("M0,0 L"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -0,0 +1,115 @@
+package pathparsing
+
+import "math"
+
+// AnchorPoints returns the anchor points of the path: the start of every
+// subpath and the end point of every segment drawn, in order. Subpaths are
+// split as by SplitSubpaths, so drawing continued after a close starts with
+// an anchor at the point it continues from. Closes add no anchor of their
+// own since they end at their subpath's start.
+func AnchorPoints(segments []PathSegmentData) []PathOffset {
+	var points []PathOffset
+	for _, subpath := range SplitSubpaths(segments) {
+		for _, seg := range subpath {
+			if seg.Command != SvgPathSegTypeClose {
+				points = append(points, seg.TargetPoint)
+			}
+		}
+	}
+	return points
+}
+
+// AnchorTangent holds the unit directions in which a path arrives at and
+// leaves an anchor point. A direction is zero where the path does not
+// arrive or leave, as at the ends of an open subpath.
+type AnchorTangent struct {
+	In, Out PathOffset
+}
+
+// AnchorTangents returns the directions in which the path arrives at and
+// leaves each of its anchor points, aligned with AnchorPoints. Curves use
+// the direction towards their first and from their last control point that
+// differs from the endpoint, and arcs their true tangent. In a closed
+// subpath the closing line arrives at the start; if it has zero length the
+// last anchor, which then coincides with the start, takes the start's
+// outgoing direction and the start the last anchor's incoming one.
+func AnchorTangents(segments []PathSegmentData) []AnchorTangent {
+	var tangents []AnchorTangent
+	for _, subpath := range SplitSubpaths(segments) {
+		first := len(tangents)
+		tangents = append(tangents, AnchorTangent{})
+		current := subpath[0].TargetPoint
+		for _, seg := range subpath[1:] {
+			if seg.Command == SvgPathSegTypeClose {
+				last := &tangents[len(tangents)-1]
+				start := &tangents[first]
+				if closing := unitDirection(seg.TargetPoint.Subtract(current)); closing != ZeroPathOffset() {
+					last.Out = closing
+					start.In = closing
+				} else if len(tangents)-1 != first {
+					last.Out = start.Out
+					start.In = last.In
+				}
+				continue
+			}
+			out, in := segmentTangents(current, seg)
+			tangents[len(tangents)-1].Out = out
+			tangents = append(tangents, AnchorTangent{In: in})
+			current = seg.TargetPoint
+		}
+	}
+	return tangents
+}
+
+// segmentTangents returns the unit directions in which an absolute segment
+// starting at start leaves its start and arrives at its end point.
+func segmentTangents(start PathOffset, seg PathSegmentData) (out, in PathOffset) {
+	var controls []PathOffset
+	switch seg.Command {
+	case SvgPathSegTypeCubicToAbs:
+		controls = []PathOffset{start, seg.Point1, seg.Point2, seg.TargetPoint}
+	case SvgPathSegTypeQuadToAbs:
+		controls = []PathOffset{start, seg.Point1, seg.TargetPoint}
+	case SvgPathSegTypeArcToAbs:
+		if arc, ok := centerArc(start, seg); ok {
+			return arc.tangent(arc.theta1), arc.tangent(arc.theta1 + arc.dtheta)
+		}
+		controls = []PathOffset{start, seg.TargetPoint}
+	default:
+		controls = []PathOffset{start, seg.TargetPoint}
+	}
+
+	for _, p := range controls[1:] {
+		if out = unitDirection(p.Subtract(start)); out != ZeroPathOffset() {
+			break
+		}
+	}
+	end := controls[len(controls)-1]
+	for i := len(controls) - 2; i >= 0; i-- {
+		if in = unitDirection(end.Subtract(controls[i])); in != ZeroPathOffset() {
+			break
+		}
+	}
+	return out, in
+}
+
+// tangent returns the unit direction of travel along the arc at angle theta.
+func (a ellipticalArc) tangent(theta float64) PathOffset {
+	sin, cos := math.Sincos(a.phi)
+	dx := -a.rx * math.Sin(theta)
+	dy := a.ry * math.Cos(theta)
+	d := PathOffset{cos*dx - sin*dy, sin*dx + cos*dy}
+	if a.dtheta < 0 {
+		d = d.Multiply(-1)
+	}
+	return unitDirection(d)
+}
+
+// unitDirection returns d scaled to unit length, or zero if d is zero.
+func unitDirection(d PathOffset) PathOffset {
+	length := math.Hypot(d.Dx, d.Dy)
+	if length == 0 {
+		return ZeroPathOffset()
+	}
+	return d.Multiply(1 / length)
+}
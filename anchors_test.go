@@ -0,0 +1,42 @@
+package pathparsing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnchorPoints(t *testing.T) {
+	expected := []PathOffset{{0, 0}, {10, 0}, {10, 10}, {0, 0}, {5, 5}, {20, 20}, {25, 20}}
+	assertPointsClose(t, expected, AnchorPoints(mustParsePath(t, "M0,0 H10 V10 Z L5,5 M20,20 q5,5 5,0")), 0)
+}
+
+func assertTangentsClose(t *testing.T, expected, actual []AnchorTangent) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		assertPointsClose(t, []PathOffset{expected[i].In, expected[i].Out}, []PathOffset{actual[i].In, actual[i].Out}, 1e-9)
+	}
+}
+
+func TestAnchorTangents(t *testing.T) {
+	right, down, left, up := PathOffset{1, 0}, PathOffset{0, 1}, PathOffset{-1, 0}, PathOffset{0, -1}
+	tangents := AnchorTangents(mustParsePath(t, "M0,0 H10 V10"))
+	assertTangentsClose(t, []AnchorTangent{{Out: right}, {In: right, Out: down}, {In: down}}, tangents)
+	if dot := tangents[1].In.Dx*tangents[1].Out.Dx + tangents[1].In.Dy*tangents[1].Out.Dy; dot != 0 {
+		t.Errorf("expected perpendicular tangents at the corner, got %v", tangents[1])
+	}
+
+	// The closing line arrives at the start.
+	diagonal := PathOffset{-math.Sqrt2 / 2, math.Sqrt2 / 2}
+	assertTangentsClose(t, []AnchorTangent{{In: diagonal, Out: up}, {In: up, Out: right}, {In: right, Out: diagonal}},
+		AnchorTangents(mustParsePath(t, "M0,10 V0 H10 Z")))
+	assertTangentsClose(t, []AnchorTangent{{In: up, Out: right}, {In: right, Out: down}, {In: down, Out: left}, {In: left, Out: up}, {In: up, Out: right}},
+		AnchorTangents(mustParsePath(t, "M0,0 H10 V10 H0 V0 Z")))
+
+	// Curves use their control points, skipping ones on the endpoints, and
+	// arcs their tangents.
+	assertTangentsClose(t, []AnchorTangent{{Out: PathOffset{math.Sqrt2 / 2, math.Sqrt2 / 2}}, {In: up, Out: up}, {In: down}},
+		AnchorTangents(mustParsePath(t, "M0,0 C0,0 10,10 10,0 A5,5 0 0 1 20,0")))
+}
@@ -0,0 +1,105 @@
+package pathparsing
+
+import "math"
+
+// ToArcsAndLines returns the path drawn with lines and circular arcs only,
+// for CNC controllers that move along arcs (G2/G3) but not along Bézier
+// curves. Lines, moves and closes are kept, and so are arcs that are already
+// circular. Cubics, quadratics and elliptical arcs are flattened and then
+// covered greedily from their start: each step takes the longest run of
+// points that a line or a circular arc through its first, middle and last
+// points passes within tol of, preferring a line. This is a best-effort fit
+// rather than an optimal one. The result is absolute, with arcs that have
+// equal radii and no rotation.
+func ToArcsAndLines(segments []PathSegmentData, tol float64) []PathSegmentData {
+	var result []PathSegmentData
+	var current, subpathStart PathOffset
+	for _, seg := range AbsoluteSegments(segments) {
+		switch seg.Command {
+		case SvgPathSegTypeMoveToAbs:
+			subpathStart = seg.TargetPoint
+			result = append(result, seg)
+		case SvgPathSegTypeLineToAbs:
+			result = append(result, seg)
+		case SvgPathSegTypeClose:
+			result = append(result, seg)
+			current = subpathStart
+			continue
+		default:
+			if arc, ok := centerArc(current, seg); ok && seg.Command == SvgPathSegTypeArcToAbs && arc.rx == arc.ry {
+				seg.Point1 = PathOffset{arc.rx, arc.rx}
+				seg.ArcAngle = 0
+				result = append(result, seg)
+				break
+			}
+			curve := []PathSegmentData{{Command: SvgPathSegTypeMoveToAbs, TargetPoint: current}, seg}
+			points := flattenContours(curve, tol/4)[0].points
+			result = append(result, fitArcsAndLines(points, tol)...)
+		}
+		current = seg.TargetPoint
+	}
+	return result
+}
+
+// fitArcsAndLines covers the polyline with lines and circular arcs within
+// tol of its points, returning the segments after its first point.
+func fitArcsAndLines(points []PathOffset, tol float64) []PathSegmentData {
+	var result []PathSegmentData
+	for i := 0; i < len(points)-1; {
+		best := PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: points[i+1]}
+		end := i + 1
+		for j := i + 2; j < len(points); j++ {
+			if seg, ok := fitRun(points[i:j+1], tol); ok {
+				best, end = seg, j
+			} else {
+				break
+			}
+		}
+		result = append(result, best)
+		i = end
+	}
+	return result
+}
+
+// fitRun returns a line or circular arc from the first to the last point
+// that passes within tol of all the points, or false if neither does.
+func fitRun(points []PathOffset, tol float64) (PathSegmentData, bool) {
+	a, b := points[0], points[len(points)-1]
+	mid := points[len(points)/2]
+	line := PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: b}
+	if fitsWithin(points, tol, func(p PathOffset) float64 {
+		q := closestOnSegment(p, a, b)
+		return math.Hypot(p.Dx-q.Dx, p.Dy-q.Dy)
+	}) {
+		return line, true
+	}
+
+	if math.Abs(cross(a, mid, b)) < 1e-12 {
+		return PathSegmentData{}, false
+	}
+	c := circleThrough3(a, mid, b)
+	if !fitsWithin(points, tol, func(p PathOffset) float64 {
+		return math.Abs(math.Hypot(p.Dx-c.center.Dx, p.Dy-c.center.Dy) - c.radius)
+	}) {
+		return PathSegmentData{}, false
+	}
+	// The arc through mid is the large one when mid and the center lie on
+	// the same side of the chord.
+	return PathSegmentData{
+		Command:     SvgPathSegTypeArcToAbs,
+		TargetPoint: b,
+		Point1:      PathOffset{c.radius, c.radius},
+		ArcSweep:    cross(a, mid, b) > 0,
+		ArcLarge:    (cross(a, b, mid) > 0) == (cross(a, b, c.center) > 0),
+	}, true
+}
+
+// fitsWithin reports whether distance is at most tol for every point.
+func fitsWithin(points []PathOffset, tol float64, distance func(PathOffset) float64) bool {
+	for _, p := range points {
+		if distance(p) > tol {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package pathparsing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToArcsAndLines(t *testing.T) {
+	const tol = 0.01
+	quarter := mustParsePath(t, "M10,0 C10,5.5228 5.5228,10 0,10")
+	fitted := ToArcsAndLines(quarter, tol)
+	if len(fitted) < 2 {
+		t.Fatalf("expected a fitted path, got %v", fitted)
+	}
+	for _, seg := range fitted[1:] {
+		if seg.Command != SvgPathSegTypeArcToAbs {
+			t.Fatalf("expected only arcs after the moveTo, got %v", SerializeSegments(fitted))
+		}
+		assertClose(t, "radius", 10, seg.Point1.Dx, 0.05)
+	}
+	assertPointsClose(t, []PathOffset{{0, 10}}, []PathOffset{fitted[len(fitted)-1].TargetPoint}, 0)
+
+	for _, c := range flattenContours(fitted, tol/10) {
+		for _, p := range c.points {
+			if _, distance := ClosestPoint(quarter, p); distance > 2*tol {
+				t.Errorf("fitted point %v is %v from the cubic", p, distance)
+			}
+		}
+	}
+
+	// Lines and circular arcs are kept, and a flat cubic becomes a line.
+	expected := "M0,0 L5,0 A5,5 0 0 1 15,0 L25,0"
+	if actual := SerializeSegments(ToArcsAndLines(mustParsePath(t, "M0,0 H5 A5,5 0 0 1 15,0 C18,0 22,0 25,0"), tol)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	// An ellipse is covered by several arcs.
+	ellipse := mustParsePath(t, "M0,0 A20,10 0 0 1 40,0")
+	fitted = ToArcsAndLines(ellipse, 0.05)
+	if len(fitted) < 3 {
+		t.Errorf("expected several arcs for an ellipse, got %v", SerializeSegments(fitted))
+	}
+	_, area := Measure(fitted, DefaultFlattenTolerance)
+	assertClose(t, "half ellipse area", math.Pi*20*10/2, area, 1)
+}
@@ -0,0 +1,68 @@
+package pathparsing
+
+import "sort"
+
+// SplitCubic splits the cubic Bézier p0..p3 at parameter t using de
+// Casteljau subdivision. The left curve covers [0, t] and the right curve
+// [t, 1]; they share the point on the curve at t.
+func SplitCubic(p0, p1, p2, p3 PathOffset, t float64) (left, right [4]PathOffset) {
+	p01 := lerp(p0, p1, t)
+	p12 := lerp(p1, p2, t)
+	p23 := lerp(p2, p3, t)
+	p012 := lerp(p01, p12, t)
+	p123 := lerp(p12, p23, t)
+	mid := lerp(p012, p123, t)
+	return [4]PathOffset{p0, p01, p012, mid}, [4]PathOffset{mid, p123, p23, p3}
+}
+
+// EvalCubic returns the point at parameter t on the cubic Bézier p0..p3.
+func EvalCubic(p0, p1, p2, p3 PathOffset, t float64) PathOffset {
+	mt := 1 - t
+	a := mt * mt * mt
+	b := 3 * mt * mt * t
+	c := 3 * mt * t * t
+	d := t * t * t
+	return PathOffset{
+		a*p0.Dx + b*p1.Dx + c*p2.Dx + d*p3.Dx,
+		a*p0.Dy + b*p1.Dy + c*p2.Dy + d*p3.Dy,
+	}
+}
+
+// EvalCubicDerivative returns the derivative with respect to t of the cubic
+// Bézier p0..p3 at parameter t, which points along the tangent.
+func EvalCubicDerivative(p0, p1, p2, p3 PathOffset, t float64) PathOffset {
+	mt := 1 - t
+	a := 3 * mt * mt
+	b := 6 * mt * t
+	c := 3 * t * t
+	return PathOffset{
+		a*(p1.Dx-p0.Dx) + b*(p2.Dx-p1.Dx) + c*(p3.Dx-p2.Dx),
+		a*(p1.Dy-p0.Dy) + b*(p2.Dy-p1.Dy) + c*(p3.Dy-p2.Dy),
+	}
+}
+
+// SplitCubicYMonotonic splits the cubic Bézier p0..p3 at the parameters
+// where its y coordinate has an extremum, so y never changes direction
+// within any of the returned spans, as a scanline fill requires. The spans
+// run from p0 to p3 in order; a cubic that is already y-monotonic is
+// returned as the only span. The control points next to each split point
+// are given its y coordinate exactly, so rounding cannot leave a span with
+// a tiny overshoot.
+func SplitCubicYMonotonic(p0, p1, p2, p3 PathOffset) [][4]PathOffset {
+	ts := cubicAxisExtrema(p0.Dy, p1.Dy, p2.Dy, p3.Dy)
+	sort.Float64s(ts)
+	spans := make([][4]PathOffset, 0, len(ts)+1)
+	rest := [4]PathOffset{p0, p1, p2, p3}
+	start := 0.0
+	for _, t := range ts {
+		if t <= start {
+			continue
+		}
+		left, right := SplitCubic(rest[0], rest[1], rest[2], rest[3], (t-start)/(1-start))
+		left[2].Dy = left[3].Dy
+		right[1].Dy = right[0].Dy
+		spans = append(spans, left)
+		rest, start = right, t
+	}
+	return append(spans, rest)
+}
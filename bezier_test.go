@@ -0,0 +1,57 @@
+package pathparsing
+
+import "testing"
+
+func TestSplitCubic(t *testing.T) {
+	p0, p1, p2, p3 := PathOffset{0, 0}, PathOffset{0, 10}, PathOffset{10, 10}, PathOffset{10, 0}
+	left, right := SplitCubic(p0, p1, p2, p3, 0.5)
+	mid := PathOffset{5, 7.5}
+	assertPointsClose(t, []PathOffset{p0, mid}, []PathOffset{left[0], left[3]}, 1e-9)
+	assertPointsClose(t, []PathOffset{mid, p3}, []PathOffset{right[0], right[3]}, 1e-9)
+	assertPointsClose(t, []PathOffset{{0, 5}, {2.5, 7.5}}, left[1:3], 1e-9)
+	assertPointsClose(t, []PathOffset{{7.5, 7.5}, {10, 5}}, right[1:3], 1e-9)
+}
+
+func TestEvalCubic(t *testing.T) {
+	p0, p1, p2, p3 := PathOffset{1, 2}, PathOffset{3, 8}, PathOffset{9, 7}, PathOffset{10, -1}
+	assertPointsClose(t, []PathOffset{p0}, []PathOffset{EvalCubic(p0, p1, p2, p3, 0)}, 1e-12)
+	assertPointsClose(t, []PathOffset{p3}, []PathOffset{EvalCubic(p0, p1, p2, p3, 1)}, 1e-12)
+
+	left, _ := SplitCubic(p0, p1, p2, p3, 0.3)
+	assertPointsClose(t, []PathOffset{left[3]}, []PathOffset{EvalCubic(p0, p1, p2, p3, 0.3)}, 1e-12)
+}
+
+func TestEvalCubicDerivative(t *testing.T) {
+	p0, p1, p2, p3 := PathOffset{1, 2}, PathOffset{3, 8}, PathOffset{9, 7}, PathOffset{10, -1}
+	assertPointsClose(t, []PathOffset{p1.Subtract(p0).Multiply(3)}, []PathOffset{EvalCubicDerivative(p0, p1, p2, p3, 0)}, 1e-12)
+	assertPointsClose(t, []PathOffset{p3.Subtract(p2).Multiply(3)}, []PathOffset{EvalCubicDerivative(p0, p1, p2, p3, 1)}, 1e-12)
+
+	const h = 1e-6
+	numeric := EvalCubic(p0, p1, p2, p3, 0.4+h).Subtract(EvalCubic(p0, p1, p2, p3, 0.4-h)).Multiply(1 / (2 * h))
+	assertPointsClose(t, []PathOffset{numeric}, []PathOffset{EvalCubicDerivative(p0, p1, p2, p3, 0.4)}, 1e-6)
+}
+
+func TestSplitCubicYMonotonic(t *testing.T) {
+	p0, p1, p2, p3 := PathOffset{0, 0}, PathOffset{0, 10}, PathOffset{10, 10}, PathOffset{10, 0}
+	spans := SplitCubicYMonotonic(p0, p1, p2, p3)
+	if len(spans) != 2 {
+		t.Fatalf("expected two spans, got %v", spans)
+	}
+	assertPointsClose(t, []PathOffset{p0, {5, 7.5}, p3}, []PathOffset{spans[0][0], spans[0][3], spans[1][3]}, 1e-9)
+	for i, span := range spans {
+		rising := span[3].Dy > span[0].Dy
+		for j := 1; j < 4; j++ {
+			if (span[j].Dy > span[j-1].Dy) != rising && span[j].Dy != span[j-1].Dy {
+				t.Errorf("span %d is not y-monotonic: %v", i, span)
+			}
+		}
+	}
+
+	// An S-shaped cubic has two extrema; a monotonic one is kept whole.
+	if spans := SplitCubicYMonotonic(PathOffset{0, 0}, PathOffset{0, 10}, PathOffset{10, -10}, PathOffset{10, 0}); len(spans) != 3 {
+		t.Errorf("expected three spans, got %v", spans)
+	}
+	if spans := SplitCubicYMonotonic(p0, PathOffset{1, 1}, PathOffset{2, 2}, PathOffset{3, 3}); len(spans) != 1 || spans[0] != [4]PathOffset{p0, {1, 1}, {2, 2}, {3, 3}} {
+		t.Errorf("expected the cubic unchanged, got %v", spans)
+	}
+}
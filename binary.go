@@ -0,0 +1,128 @@
+package pathparsing
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Tags of the binary path format written by BinaryProxy. Each command is
+// its tag byte followed by its coordinates as little-endian float64 values.
+const (
+	binaryTagMoveTo  byte = 'M'
+	binaryTagLineTo  byte = 'L'
+	binaryTagCubicTo byte = 'C'
+	binaryTagClose   byte = 'Z'
+)
+
+// BinaryProxy writes the commands sent to it to an io.Writer in a compact
+// binary format that ReadBinaryPath decodes: a tag byte per command ('M',
+// 'L', 'C' or 'Z') followed by its coordinates as little-endian float64
+// values. The first write error is recorded and returned by Err, and nothing
+// is written after it. Wrap the writer in a bufio.Writer to avoid a write
+// call per command.
+type BinaryProxy struct {
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+// NewBinaryProxy creates a BinaryProxy writing to w.
+func NewBinaryProxy(w io.Writer) *BinaryProxy {
+	return &BinaryProxy{w: w}
+}
+
+// MoveTo writes a moveTo command.
+func (p *BinaryProxy) MoveTo(x, y float64) {
+	p.write(binaryTagMoveTo, x, y)
+}
+
+// LineTo writes a lineTo command.
+func (p *BinaryProxy) LineTo(x, y float64) {
+	p.write(binaryTagLineTo, x, y)
+}
+
+// CubicTo writes a cubicTo command.
+func (p *BinaryProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.write(binaryTagCubicTo, x1, y1, x2, y2, x3, y3)
+}
+
+// Close writes a close command.
+func (p *BinaryProxy) Close() {
+	p.write(binaryTagClose)
+}
+
+// Err returns the first error the underlying writer returned, or nil.
+func (p *BinaryProxy) Err() error {
+	return p.err
+}
+
+func (p *BinaryProxy) write(tag byte, coords ...float64) {
+	if p.err != nil {
+		return
+	}
+	p.buf = append(p.buf[:0], tag)
+	for _, v := range coords {
+		p.buf = binary.LittleEndian.AppendUint64(p.buf, math.Float64bits(v))
+	}
+	_, p.err = p.w.Write(p.buf)
+}
+
+// binaryCoordinateCount returns how many coordinates follow the tag, or -1
+// for an unknown tag.
+func binaryCoordinateCount(tag byte) int {
+	switch tag {
+	case binaryTagMoveTo, binaryTagLineTo:
+		return 2
+	case binaryTagCubicTo:
+		return 6
+	case binaryTagClose:
+		return 0
+	}
+	return -1
+}
+
+// ReadBinaryPath decodes commands written by a BinaryProxy from r and sends
+// them to path until r is exhausted. Input ending inside a command yields
+// io.ErrUnexpectedEOF and an unknown tag an error; commands decoded before
+// the problem have already been sent.
+func ReadBinaryPath(r io.Reader, path PathProxy) error {
+	var tag [1]byte
+	var coords [6]float64
+	var buf [6 * 8]byte
+	for offset := 0; ; {
+		if _, err := io.ReadFull(r, tag[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		n := binaryCoordinateCount(tag[0])
+		if n < 0 {
+			return fmt.Errorf("unknown binary path command tag %#x at offset %d", tag[0], offset)
+		}
+		if _, err := io.ReadFull(r, buf[:n*8]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		for i := 0; i < n; i++ {
+			coords[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[i*8:]))
+		}
+		offset += 1 + n*8
+
+		switch tag[0] {
+		case binaryTagMoveTo:
+			path.MoveTo(coords[0], coords[1])
+		case binaryTagLineTo:
+			path.LineTo(coords[0], coords[1])
+		case binaryTagCubicTo:
+			path.CubicTo(coords[0], coords[1], coords[2], coords[3], coords[4], coords[5])
+		case binaryTagClose:
+			path.Close()
+		}
+	}
+}
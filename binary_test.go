@@ -0,0 +1,85 @@
+package pathparsing
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestBinaryProxyRoundTrip(t *testing.T) {
+	const svg = "M0.1,0.2 L10,0 C10,5 5,10 0,10 Z M20,20 h5 a3,4 30 1 1 5,5"
+	expected := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath(svg, expected); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	proxy := NewBinaryProxy(w)
+	if err := WriteSvgPathDataToPath(svg, proxy); err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := NewRecordingProxy()
+	if err := ReadBinaryPath(bytes.NewReader(buf.Bytes()), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Diff(expected.Commands()); err != nil {
+		t.Error(err)
+	}
+
+	if got, want := buf.Bytes(), []byte{'M', 0x9a, 0x99, 0x99, 0x99, 0x99, 0x99, 0xb9, 0x3f}; !bytes.HasPrefix(got, want) {
+		t.Errorf("expected the first command to start with %x, got %x", want, got[:len(want)])
+	}
+}
+
+func TestReadBinaryPathErrors(t *testing.T) {
+	var buf bytes.Buffer
+	proxy := NewBinaryProxy(&buf)
+	proxy.MoveTo(1, 2)
+	proxy.LineTo(3, 4)
+	data := buf.Bytes()
+
+	if err := ReadBinaryPath(bytes.NewReader(data[:len(data)-3]), NewRecordingProxy()); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF for truncated input, got %v", err)
+	}
+	recorded := NewRecordingProxy()
+	err := ReadBinaryPath(bytes.NewReader(append(data[:17:17], 'X')), recorded)
+	if err == nil {
+		t.Error("expected an error for an unknown tag")
+	}
+	if len(recorded.Commands()) != 1 {
+		t.Errorf("expected the command before the unknown tag to be sent, got %v", recorded.Commands())
+	}
+	if err := ReadBinaryPath(bytes.NewReader(nil), NewRecordingProxy()); err != nil {
+		t.Errorf("expected empty input to decode to nothing, got %v", err)
+	}
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestBinaryProxyWriteError(t *testing.T) {
+	w := &failingWriter{}
+	proxy := NewBinaryProxy(w)
+	proxy.MoveTo(0, 0)
+	proxy.LineTo(1, 1)
+	proxy.Close()
+	if proxy.Err() == nil || w.writes != 1 {
+		t.Errorf("expected the first error to stop writing, got %v after %d writes", proxy.Err(), w.writes)
+	}
+}
@@ -0,0 +1,202 @@
+package pathparsing
+
+import "math"
+
+// Bounds returns the corners of the tight axis-aligned bounding box of the
+// path, computed analytically from the segments: curves contribute their
+// extrema and arcs the outermost points of their ellipse within the swept
+// angle, so no flattening or arc decomposition is involved. Every point the
+// path moves to is included. A path without segments has zero bounds.
+func Bounds(segments []PathSegmentData) (lo, hi PathOffset) {
+	first := true
+	add := func(p PathOffset) {
+		if first {
+			lo, hi = p, p
+			first = false
+			return
+		}
+		lo = PathOffset{math.Min(lo.Dx, p.Dx), math.Min(lo.Dy, p.Dy)}
+		hi = PathOffset{math.Max(hi.Dx, p.Dx), math.Max(hi.Dy, p.Dy)}
+	}
+
+	current := ZeroPathOffset()
+	for _, seg := range AbsoluteSegments(segments) {
+		switch seg.Command {
+		case SvgPathSegTypeCubicToAbs:
+			for _, t := range cubicExtrema(current, seg.Point1, seg.Point2, seg.TargetPoint) {
+				add(EvalCubic(current, seg.Point1, seg.Point2, seg.TargetPoint, t))
+			}
+		case SvgPathSegTypeQuadToAbs:
+			for _, t := range quadExtrema(current, seg.Point1, seg.TargetPoint) {
+				add(lerp(lerp(current, seg.Point1, t), lerp(seg.Point1, seg.TargetPoint, t), t))
+			}
+		case SvgPathSegTypeArcToAbs:
+			if arc, ok := centerArc(current, seg); ok {
+				for _, p := range arc.extrema() {
+					add(p)
+				}
+			}
+		}
+		add(seg.TargetPoint)
+		current = seg.TargetPoint
+	}
+	return lo, hi
+}
+
+// cubicExtrema returns the parameters in (0, 1) where the cubic's tangent is
+// horizontal or vertical.
+func cubicExtrema(p0, p1, p2, p3 PathOffset) []float64 {
+	return append(cubicAxisExtrema(p0.Dx, p1.Dx, p2.Dx, p3.Dx), cubicAxisExtrema(p0.Dy, p1.Dy, p2.Dy, p3.Dy)...)
+}
+
+// cubicAxisExtrema returns the parameters in (0, 1) where one coordinate of
+// the cubic, with control values a..d, has zero derivative.
+func cubicAxisExtrema(a, b, c, d float64) []float64 {
+	var ts []float64
+	// The derivative divided by 3 is qa*t*t + qb*t + qc.
+	qa := -a + 3*b - 3*c + d
+	qb := 2 * (a - 2*b + c)
+	qc := b - a
+	for _, t := range quadraticRoots(qa, qb, qc) {
+		if t > 0 && t < 1 {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+// quadExtrema returns the parameters in (0, 1) where the quadratic's tangent
+// is horizontal or vertical.
+func quadExtrema(p0, p1, p2 PathOffset) []float64 {
+	var ts []float64
+	axis := func(a, b, c float64) {
+		if d := a - 2*b + c; d != 0 {
+			if t := (a - b) / d; t > 0 && t < 1 {
+				ts = append(ts, t)
+			}
+		}
+	}
+	axis(p0.Dx, p1.Dx, p2.Dx)
+	axis(p0.Dy, p1.Dy, p2.Dy)
+	return ts
+}
+
+// quadraticRoots returns the real roots of a*t*t + b*t + c, solving the
+// linear equation when a is zero.
+func quadraticRoots(a, b, c float64) []float64 {
+	if math.Abs(a) < 1e-12 {
+		if b == 0 {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return nil
+	}
+	sq := math.Sqrt(discriminant)
+	return []float64{(-b + sq) / (2 * a), (-b - sq) / (2 * a)}
+}
+
+// ellipticalArc is an arc in center parameterization.
+type ellipticalArc struct {
+	center PathOffset
+	rx, ry float64
+	// phi is the rotation of the x axis in radians.
+	phi float64
+	// theta1 is the start angle and dtheta the signed swept angle, both in
+	// radians and measured before the ellipse is scaled and rotated.
+	theta1, dtheta float64
+}
+
+// centerArc converts an absolute arc segment starting at start to center
+// parameterization, enlarging radii that are too small to reach the target
+// as SVG does. It returns false for arcs drawn as a line or not at all.
+func centerArc(start PathOffset, seg PathSegmentData) (ellipticalArc, bool) {
+	rx := math.Abs(seg.Point1.Dx)
+	ry := math.Abs(seg.Point1.Dy)
+	if rx == 0 || ry == 0 || start == seg.TargetPoint {
+		return ellipticalArc{}, false
+	}
+	phi := seg.ArcAngle * math.Pi / 180
+	sin, cos := math.Sincos(phi)
+
+	// Step 1: the midpoint in the ellipse's unrotated frame.
+	mx := (start.Dx - seg.TargetPoint.Dx) / 2
+	my := (start.Dy - seg.TargetPoint.Dy) / 2
+	x1 := cos*mx + sin*my
+	y1 := -sin*mx + cos*my
+
+	scale := arcRadiiScale(start, seg)
+	rx *= scale
+	ry *= scale
+
+	// Step 2: the center in the unrotated frame.
+	numerator := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	denominator := rx*rx*y1*y1 + ry*ry*x1*x1
+	factor := math.Sqrt(math.Max(0, numerator/denominator))
+	if seg.ArcLarge == seg.ArcSweep {
+		factor = -factor
+	}
+	cx1 := factor * rx * y1 / ry
+	cy1 := -factor * ry * x1 / rx
+
+	// Step 3: the center in user space.
+	center := PathOffset{
+		cos*cx1 - sin*cy1 + (start.Dx+seg.TargetPoint.Dx)/2,
+		sin*cx1 + cos*cy1 + (start.Dy+seg.TargetPoint.Dy)/2,
+	}
+
+	// Step 4: the start and swept angles.
+	theta1 := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	theta2 := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx)
+	dtheta := theta2 - theta1
+	if seg.ArcSweep && dtheta < 0 {
+		dtheta += 2 * math.Pi
+	} else if !seg.ArcSweep && dtheta > 0 {
+		dtheta -= 2 * math.Pi
+	}
+	if !isFinite(dtheta) || !isFiniteOffset(center) {
+		return ellipticalArc{}, false
+	}
+	return ellipticalArc{center, rx, ry, phi, theta1, dtheta}, true
+}
+
+// point returns the point of the ellipse at angle theta.
+func (a ellipticalArc) point(theta float64) PathOffset {
+	sin, cos := math.Sincos(a.phi)
+	x := a.rx * math.Cos(theta)
+	y := a.ry * math.Sin(theta)
+	return PathOffset{a.center.Dx + cos*x - sin*y, a.center.Dy + sin*x + cos*y}
+}
+
+// contains reports whether the angle theta lies within the swept range.
+func (a ellipticalArc) contains(theta float64) bool {
+	d := math.Mod(theta-a.theta1, 2*math.Pi)
+	if a.dtheta < 0 {
+		d = -math.Mod(a.theta1-theta, 2*math.Pi)
+		if d > 0 {
+			d -= 2 * math.Pi
+		}
+		return d >= a.dtheta
+	}
+	if d < 0 {
+		d += 2 * math.Pi
+	}
+	return d <= a.dtheta
+}
+
+// extrema returns the points of the arc where its tangent is horizontal or
+// vertical, excluding its endpoints.
+func (a ellipticalArc) extrema() []PathOffset {
+	sin, cos := math.Sincos(a.phi)
+	thetaX := math.Atan2(-a.ry*sin, a.rx*cos)
+	thetaY := math.Atan2(a.ry*cos, a.rx*sin)
+	var points []PathOffset
+	for _, theta := range []float64{thetaX, thetaX + math.Pi, thetaY, thetaY + math.Pi} {
+		if a.contains(theta) {
+			points = append(points, a.point(theta))
+		}
+	}
+	return points
+}
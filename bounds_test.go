@@ -0,0 +1,41 @@
+package pathparsing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoundsQuarterArc(t *testing.T) {
+	lo, hi := Bounds(mustParsePath(t, "M0,0 A10,10 0 0 1 14.142135623730951,0"))
+	assertClose(t, "min x", 0, lo.Dx, 1e-9)
+	assertClose(t, "max x", 10*math.Sqrt2, hi.Dx, 1e-9)
+	assertClose(t, "min y", 5*math.Sqrt2-10, lo.Dy, 1e-9)
+	assertClose(t, "max y", 0, hi.Dy, 1e-9)
+}
+
+func TestBoundsMatchesFlattening(t *testing.T) {
+	paths := []string{
+		"M10,10 C0,40 60,-20 50,30",
+		"M10,10 Q40,-20 50,30 T90,10",
+		"M10,10 A30,15 30 1 1 60,20",
+		"M10,10 a30,15 -60 0 0 50,10 z",
+		"M0,0 A5,5 0 0 1 20,0",
+	}
+	for _, svg := range paths {
+		lo, hi := Bounds(mustParsePath(t, svg))
+		flatMin := PathOffset{math.Inf(1), math.Inf(1)}
+		flatMax := PathOffset{math.Inf(-1), math.Inf(-1)}
+		for _, c := range flattenContours(mustParsePath(t, svg), 1e-4) {
+			for _, p := range c.points {
+				flatMin = PathOffset{math.Min(flatMin.Dx, p.Dx), math.Min(flatMin.Dy, p.Dy)}
+				flatMax = PathOffset{math.Max(flatMax.Dx, p.Dx), math.Max(flatMax.Dy, p.Dy)}
+			}
+		}
+		// Arcs are flattened from their cubic approximation, which strays
+		// from the ellipse by up to about 0.03% of the radius.
+		assertClose(t, svg+" min x", flatMin.Dx, lo.Dx, 0.01)
+		assertClose(t, svg+" min y", flatMin.Dy, lo.Dy, 0.01)
+		assertClose(t, svg+" max x", flatMax.Dx, hi.Dx, 0.01)
+		assertClose(t, svg+" max y", flatMax.Dy, hi.Dy, 0.01)
+	}
+}
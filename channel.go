@@ -0,0 +1,111 @@
+package pathparsing
+
+// CommandKind identifies the PathProxy method a PathCommand stands for.
+type CommandKind int
+
+const (
+	// CommandMoveTo is a MoveTo call.
+	CommandMoveTo CommandKind = iota
+	// CommandLineTo is a LineTo call.
+	CommandLineTo
+	// CommandCubicTo is a CubicTo call.
+	CommandCubicTo
+	// CommandClose is a Close call.
+	CommandClose
+)
+
+// PathCommand is a single PathProxy call. MoveTo and LineTo store their
+// point in Points[0]; CubicTo stores its two control points and end point in
+// order. Unused points are zero.
+type PathCommand struct {
+	Kind   CommandKind
+	Points [3]PathOffset
+}
+
+// ChannelProxy sends the commands written to it as PathCommands on a
+// buffered channel, so they can be consumed by another goroutine.
+//
+// The goroutine writing to the proxy owns the channel: it must call Finish
+// once the path is written, which closes the channel and ends the consumer's
+// range loop. A consumer that stops early closes the done channel passed to
+// NewChannelProxy; the proxy then drops all further commands instead of
+// blocking, and Stopped reports true.
+type ChannelProxy struct {
+	commands chan PathCommand
+	done     <-chan struct{}
+	stopped  bool
+}
+
+// NewChannelProxy creates a ChannelProxy whose channel buffers up to buffer
+// commands. The done channel may be nil if the consumer always drains the
+// channel.
+func NewChannelProxy(buffer int, done <-chan struct{}) *ChannelProxy {
+	return &ChannelProxy{
+		commands: make(chan PathCommand, buffer),
+		done:     done,
+	}
+}
+
+// Commands returns the channel the commands are sent on.
+func (p *ChannelProxy) Commands() <-chan PathCommand {
+	return p.commands
+}
+
+// MoveTo sends a CommandMoveTo.
+func (p *ChannelProxy) MoveTo(x, y float64) {
+	p.send(PathCommand{Kind: CommandMoveTo, Points: [3]PathOffset{{x, y}}})
+}
+
+// LineTo sends a CommandLineTo.
+func (p *ChannelProxy) LineTo(x, y float64) {
+	p.send(PathCommand{Kind: CommandLineTo, Points: [3]PathOffset{{x, y}}})
+}
+
+// CubicTo sends a CommandCubicTo.
+func (p *ChannelProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.send(PathCommand{Kind: CommandCubicTo, Points: [3]PathOffset{{x1, y1}, {x2, y2}, {x3, y3}}})
+}
+
+// Close sends a CommandClose. It does not close the channel; see Finish.
+func (p *ChannelProxy) Close() {
+	p.send(PathCommand{Kind: CommandClose})
+}
+
+// Finish closes the channel. No commands may be written afterwards.
+func (p *ChannelProxy) Finish() {
+	close(p.commands)
+}
+
+// Stopped reports whether the consumer closed the done channel and commands
+// are being dropped.
+func (p *ChannelProxy) Stopped() bool {
+	return p.stopped
+}
+
+// send sends the command unless the consumer has stopped.
+func (p *ChannelProxy) send(command PathCommand) {
+	if p.stopped {
+		return
+	}
+	select {
+	case p.commands <- command:
+	case <-p.done:
+		p.stopped = true
+	}
+}
+
+// StreamSvgPathData parses SVG path data in a new goroutine and streams the
+// commands on the returned channel, which is closed when parsing completes.
+// The parse error, or nil, is then sent on the error channel. Closing done
+// makes the goroutine drop the remaining commands so it does not block on a
+// consumer that stopped reading.
+func StreamSvgPathData(svg string, buffer int, done <-chan struct{}) (<-chan PathCommand, <-chan error) {
+	proxy := NewChannelProxy(buffer, done)
+	errc := make(chan error, 1)
+	go func() {
+		err := WriteSvgPathDataToPath(svg, proxy)
+		proxy.Finish()
+		errc <- err
+	}()
+	return proxy.Commands(), errc
+}
@@ -0,0 +1,40 @@
+package pathparsing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStreamSvgPathData(t *testing.T) {
+	commands, errc := StreamSvgPathData("M1,2 L3,4 C5,6 7,8 9,10 Z", 1, nil)
+	var actual []PathCommand
+	for command := range commands {
+		actual = append(actual, command)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	expected := []PathCommand{
+		{Kind: CommandMoveTo, Points: [3]PathOffset{{1, 2}}},
+		{Kind: CommandLineTo, Points: [3]PathOffset{{3, 4}}},
+		{Kind: CommandCubicTo, Points: [3]PathOffset{{5, 6}, {7, 8}, {9, 10}}},
+		{Kind: CommandClose},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestChannelProxyStopsEarly(t *testing.T) {
+	done := make(chan struct{})
+	commands, errc := StreamSvgPathData(repeatedPathData(100), 0, done)
+	if command := <-commands; command.Kind != CommandMoveTo {
+		t.Errorf("expected a moveTo first, got %v", command)
+	}
+	close(done)
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	for range commands {
+	}
+}
@@ -0,0 +1,124 @@
+package pathparsing
+
+import "math"
+
+// RoundCorners returns the path with every corner rounded by a circular arc
+// of the given radius that is tangent to both edges meeting there, cutting
+// the edges back to where the arc touches them. Convex and concave corners
+// are rounded alike. Where an edge is too short for the arcs at both of its
+// ends, each arc may use up to half of it and its radius shrinks to fit.
+// The path is flattened first, so curves become runs of short edges whose
+// corners are rounded as well; the ends of open subpaths are kept sharp.
+// The result consists of absolute moveTo, lineTo, arc and close segments.
+func RoundCorners(segments []PathSegmentData, radius float64) []PathSegmentData {
+	var result []PathSegmentData
+	add := func(command SvgPathSegType, p PathOffset) {
+		result = append(result, PathSegmentData{Command: command, TargetPoint: p})
+	}
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		points := dedupePoints(c.points)
+		if c.closed {
+			points = cleanPolygon(points)
+		}
+		if len(points) < 3 {
+			if len(points) > 0 {
+				add(SvgPathSegTypeMoveToAbs, points[0])
+				for _, p := range points[1:] {
+					add(SvgPathSegTypeLineToAbs, p)
+				}
+				if c.closed {
+					add(SvgPathSegTypeClose, points[0])
+				}
+			}
+			continue
+		}
+
+		n := len(points)
+		corner := func(i int) (roundedCorner, bool) {
+			return roundCorner(points[(i+n-1)%n], points[i], points[(i+1)%n], radius)
+		}
+		// addCorner adds the segments drawing the corner at points[i],
+		// leaving out the line to an arc starting where the last one ended.
+		addCorner := func(i int) {
+			rounded, ok := corner(i)
+			for _, seg := range roundedCornerSegments(points[i], rounded, ok) {
+				if seg.Command != SvgPathSegTypeLineToAbs || seg.TargetPoint != result[len(result)-1].TargetPoint {
+					result = append(result, seg)
+				}
+			}
+		}
+		if !c.closed {
+			add(SvgPathSegTypeMoveToAbs, points[0])
+			for i := 1; i < n-1; i++ {
+				addCorner(i)
+			}
+			add(SvgPathSegTypeLineToAbs, points[n-1])
+			continue
+		}
+
+		first, ok := corner(0)
+		if ok {
+			add(SvgPathSegTypeMoveToAbs, first.end)
+		} else {
+			add(SvgPathSegTypeMoveToAbs, points[0])
+		}
+		for i := 1; i < n; i++ {
+			addCorner(i)
+		}
+		if ok {
+			addCorner(0)
+		}
+		add(SvgPathSegTypeClose, result[len(result)-1].TargetPoint)
+	}
+	return result
+}
+
+// roundedCorner is the arc replacing a corner: it runs from start on the
+// incoming edge to end on the outgoing edge.
+type roundedCorner struct {
+	start, end PathOffset
+	radius     float64
+	sweep      bool
+}
+
+// roundCorner returns the arc of the given radius rounding the corner at v
+// between the edges from prev and to next, shrunk to use at most half of
+// each edge. It returns false where the edges are collinear or turn back on
+// themselves and so there is no corner to round.
+func roundCorner(prev, v, next PathOffset, radius float64) (roundedCorner, bool) {
+	in := v.Subtract(prev)
+	out := next.Subtract(v)
+	inLength := math.Hypot(in.Dx, in.Dy)
+	outLength := math.Hypot(out.Dx, out.Dy)
+	turn := angleBetween(in.Direction(), out.Direction())
+	if radius <= 0 || turn < 1e-9 || turn > math.Pi-1e-9 {
+		return roundedCorner{}, false
+	}
+
+	// The arc touches each edge at this distance from the corner.
+	halfTurn := math.Tan(turn / 2)
+	distance := math.Min(radius*halfTurn, math.Min(inLength, outLength)/2)
+	return roundedCorner{
+		start:  v.Subtract(in.Multiply(distance / inLength)),
+		end:    v.Add(out.Multiply(distance / outLength)),
+		radius: distance / halfTurn,
+		sweep:  in.Dx*out.Dy-in.Dy*out.Dx > 0,
+	}, true
+}
+
+// roundedCornerSegments returns the segments drawing the corner at v, which
+// is a line to the corner itself if it is not rounded.
+func roundedCornerSegments(v PathOffset, corner roundedCorner, ok bool) []PathSegmentData {
+	if !ok {
+		return []PathSegmentData{{Command: SvgPathSegTypeLineToAbs, TargetPoint: v}}
+	}
+	return []PathSegmentData{
+		{Command: SvgPathSegTypeLineToAbs, TargetPoint: corner.start},
+		{
+			Command:     SvgPathSegTypeArcToAbs,
+			TargetPoint: corner.end,
+			Point1:      PathOffset{corner.radius, corner.radius},
+			ArcSweep:    corner.sweep,
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package pathparsing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundCorners(t *testing.T) {
+	rounded := RoundCorners(mustParsePath(t, "M0,0 H10 V10 H0 Z"), 2)
+	expected := "M2,0 L8,0 A2,2 0 0 1 10,2 L10,8 A2,2 0 0 1 8,10 L2,10 A2,2 0 0 1 0,8 L0,2 A2,2 0 0 1 2,0 Z"
+	if actual := SerializeSegments(rounded); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	assertClose(t, "rounded area", 100-(4-math.Pi)*4, FilledArea(rounded, false), 0.1)
+
+	// The corners of a 2x2 square can use at most half of each edge.
+	expected = "M1,0 A1,1 0 0 1 2,1 A1,1 0 0 1 1,2 A1,1 0 0 1 0,1 A1,1 0 0 1 1,0 Z"
+	if actual := SerializeSegments(RoundCorners(mustParsePath(t, "M0,0 H2 V2 H0 Z"), 5)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	// A concave corner turns the other way, and the ends of an open path
+	// and straight runs stay as they are.
+	expected = "M0,0 L5,0 L9,0 A1,1 0 0 0 10,-1 L10,-10"
+	if actual := SerializeSegments(RoundCorners(mustParsePath(t, "M0,0 H5 H10 V-10"), 1)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
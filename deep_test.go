@@ -1,7 +1,9 @@
 package pathparsing
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"testing"
 )
 
@@ -74,3 +76,314 @@ func TestParsePathDeepTest(t *testing.T) {
 		"close()",
 	})
 }
+
+func TestParsePathWhitespaceDeepTest(t *testing.T) {
+	lines := []string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(10.0000, 10.0000)",
+	}
+	assertValidPathDeep("M0 0 L10 10", lines)
+	assertValidPathDeep("M0\t0\nL10\r\n10", lines)
+	assertValidPathDeep("M0\t0\tL10\t10", lines)
+	assertValidPathDeep("M0\r\n0\r\nL10\r\n10\r\n", lines)
+
+	arc := []string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(0.0000, -2.7614, 2.2386, -5.0000, 5.0000, -5.0000)",
+		"cubicTo(7.7614, -5.0000, 10.0000, -2.7614, 10.0000, -0.0000)",
+	}
+	assertValidPathDeep("M0 0 A5 5 0 1 1 10 0", arc)
+	assertValidPathDeep("M0\t0\tA5\t5\t0\t1\t1\t10\t0", arc)
+	assertValidPathDeep("M0\r\n0\r\nA5\r\n5\r\n0\r\n1\r\n1\r\n10\r\n0", arc)
+	assertValidPathDeep("M0,0 A5,5,0\t,\t1\t,\t1\t,\t10,0", arc)
+	assertValidPathDeep("M0,0\tA5\t5\t0\t1\t1\t10\t0\t", arc)
+}
+
+func TestDrawCoincidentArcsAsCircleDeepTest(t *testing.T) {
+	proxy := NewDeepTestPathProxy([]string{
+		"moveTo(5.0000, 5.0000)",
+		"lineTo(5.0000, 5.0000)",
+	})
+	WriteSvgPathDataToPath("M5,5 A5,5 0 1 1 5,5", proxy)
+	proxy.Validate()
+
+	proxy = NewDeepTestPathProxy([]string{
+		"moveTo(5.0000, 5.0000)",
+		"cubicTo(5.0000, 2.2386, 7.2386, 0.0000, 10.0000, 0.0000)",
+		"cubicTo(12.7614, 0.0000, 15.0000, 2.2386, 15.0000, 5.0000)",
+		"cubicTo(15.0000, 7.7614, 12.7614, 10.0000, 10.0000, 10.0000)",
+		"cubicTo(7.2386, 10.0000, 5.0000, 7.7614, 5.0000, 5.0000)",
+	})
+	err := WriteSvgPathDataToPathWithOptions("M5,5 A5,5 0 1 1 5,5", proxy, Options{DrawCoincidentArcsAsCircle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy.Validate()
+}
+
+func TestIntegerMantissaExponentDeepTest(t *testing.T) {
+	assertValidPathDeep("M3e2,0", []string{
+		"moveTo(300.0000, 0.0000)",
+	})
+	assertValidPathDeep("M0,0 L1e1,1e1", []string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(10.0000, 10.0000)",
+	})
+	assertValidPathDeep("M0,0 L1E-1,2e+1", []string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(0.1000, 20.0000)",
+	})
+}
+
+func TestMinusSeparatorDeepTest(t *testing.T) {
+	assertValidPathDeep("M10-5 L20-10", []string{
+		"moveTo(10.0000, -5.0000)",
+		"lineTo(20.0000, -10.0000)",
+	})
+	assertValidPathDeep("M1.5-2.5", []string{
+		"moveTo(1.5000, -2.5000)",
+	})
+	assertValidPathDeep("M.5-.5-.5-.5", []string{
+		"moveTo(0.5000, -0.5000)",
+		"lineTo(-0.5000, -0.5000)",
+	})
+	assertValidPathDeep("M1e-2-3", []string{
+		"moveTo(0.0100, -3.0000)",
+	})
+	assertValidPathDeep("M0,0 h-1-2v-3-4", []string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(-1.0000, 0.0000)",
+		"lineTo(-3.0000, 0.0000)",
+		"lineTo(-3.0000, -3.0000)",
+		"lineTo(-3.0000, -7.0000)",
+	})
+	assertValidPathDeep("M0,0 C1-2-3-4-5-6", []string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(1.0000, -2.0000, -3.0000, -4.0000, -5.0000, -6.0000)",
+	})
+	assertValidPathDeep("M0,0 A5-5 0 0 1-10-10", []string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(-2.7614, 2.7614, -7.2386, 2.7614, -10.0000, -0.0000)",
+		"cubicTo(-12.7614, -2.7614, -12.7614, -7.2386, -10.0000, -10.0000)",
+	})
+}
+
+func TestArcFlagSeparationDeepTest(t *testing.T) {
+	arc := []string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(-0.0000, 2.7614, -2.2386, 5.0000, -5.0000, 5.0000)",
+		"cubicTo(-7.7614, 5.0000, -10.0000, 2.7614, -10.0000, -0.0000)",
+		"cubicTo(-10.0000, -2.7614, -7.7614, -5.0000, -5.0000, -5.0000)",
+	}
+	assertValidPathDeep("M0,0 A5 5 0 1 1 -5 -5", arc)
+	assertValidPathDeep("M0,0 A5 5 0 11-5-5", arc)
+	assertValidPathDeep("M0,0 A5 5 0 1 1-5-5", arc)
+	assertValidPathDeep("M0,0 A5,5,0,1,1,-5,-5", arc)
+	assertValidPathDeep("M0,0 A5 5 0 1,1 -5,-5", arc)
+	assertValidPathDeep("M0,0 a5 5 0 11-5-5", arc)
+}
+
+func TestExplicitMoveToAfterCloseDeepTest(t *testing.T) {
+	assertValidPathDeep("M0,0 L10,0 Z L20,0", []string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(10.0000, 0.0000)",
+		"close()",
+		"lineTo(20.0000, 0.0000)",
+	})
+
+	proxy := NewDeepTestPathProxy([]string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(10.0000, 0.0000)",
+		"close()",
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(20.0000, 0.0000)",
+		"close()",
+		"moveTo(30.0000, 0.0000)",
+		"lineTo(40.0000, 0.0000)",
+	})
+	err := WriteSvgPathDataToPathWithOptions("M0,0 L10,0 Z L20,0 Z M30,0 L40,0", proxy, Options{ExplicitMoveToAfterClose: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy.Validate()
+}
+
+func TestCustomDelimiterDeepTest(t *testing.T) {
+	proxy := NewDeepTestPathProxy([]string{
+		"moveTo(1.0000, 2.0000)",
+		"lineTo(3.0000, 4.0000)",
+		"cubicTo(10.0000, 0.0000, 0.0000, 10.0000, 0.0000, 0.0000)",
+	})
+	err := WriteSvgPathDataToPathWithOptions("M1;2 L3 ; 4 C10;0;0;10;0;0", proxy, Options{Delimiter: ';'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy.Validate()
+
+	if err := WriteSvgPathDataToPath("M1;2", NewRecordingProxy()); err == nil {
+		t.Error("expected semicolons to be rejected by default")
+	}
+}
+
+func TestArcResolutionDeepTest(t *testing.T) {
+	proxy := &pointRecordingPathProxy{}
+	err := WriteSvgPathDataToPathWithOptions("M10,0 A10,10 0 0 1 0,10", proxy, Options{ArcResolution: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected []PathOffset
+	for i := 0; i <= 9; i++ {
+		sin, cos := math.Sincos(float64(i) * math.Pi / 18)
+		expected = append(expected, PathOffset{10 * cos, 10 * sin})
+	}
+	assertPointsClose(t, expected, proxy.points, 1e-9)
+
+	// A fraction of a step still needs its own line, and the full ellipse
+	// drawn for coincident endpoints is split into two half arcs.
+	proxy = &pointRecordingPathProxy{}
+	err = WriteSvgPathDataToPathWithOptions("M0,0 A5,5 0 0 1 10,0 M20,0 A5,5 0 1 1 20,0", proxy, Options{ArcResolution: 50, DrawCoincidentArcsAsCircle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proxy.points) != 1+4+1+8 {
+		t.Errorf("expected a half circle of four lines and a circle of eight, got %v", proxy.points)
+	}
+
+	// A tiny resolution is capped rather than writing billions of lines or
+	// overflowing the count.
+	for _, resolution := range []float64{1e-9, 1e-300} {
+		proxy = &pointRecordingPathProxy{}
+		err = WriteSvgPathDataToPathWithOptions("M10,0 A10,10 0 0 1 0,10", proxy, Options{ArcResolution: resolution})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(proxy.points) != 1+maxArcLines {
+			t.Errorf("resolution %v: expected %d lines, got %d", resolution, maxArcLines, len(proxy.points)-1)
+		}
+		if last := proxy.points[len(proxy.points)-1]; last != (PathOffset{0, 10}) {
+			t.Errorf("resolution %v: expected the arc to end at its target, got %v", resolution, last)
+		}
+	}
+}
+
+func TestArcRadiiCorrectedDeepTest(t *testing.T) {
+	var corrected []PathSegmentData
+	var scales []float64
+	options := Options{OnArcRadiiCorrected: func(arc PathSegmentData, scale float64) {
+		corrected = append(corrected, arc)
+		scales = append(scales, scale)
+	}}
+	// The second arc's radii are half of what it needs to reach its end
+	// point; the first fits exactly and the third spans more than needed.
+	recorder := NewRecordingProxy()
+	err := WriteSvgPathDataToPathWithOptions("M0,0 A5,5 0 0 1 10,0 a5,5 0 0 1 20,0 A50,50 0 0 1 40,0", recorder, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The arc is drawn with the corrected radii.
+	expected := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath("M0,0 A5,5 0 0 1 10,0 a10,10 0 0 1 20,0 A50,50 0 0 1 40,0", expected); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Diff(expected.Commands()); err != nil {
+		t.Error(err)
+	}
+	if len(corrected) != 1 {
+		t.Fatalf("expected one corrected arc, got %v", corrected)
+	}
+	if corrected[0].TargetPoint != (PathOffset{30, 0}) {
+		t.Errorf("expected the corrected arc made absolute, got %v", corrected[0])
+	}
+	if math.Abs(scales[0]-2) > 1e-12 {
+		t.Errorf("expected the radii doubled, got a scale of %v", scales[0])
+	}
+}
+
+func TestArcRotationInScientificNotationDeepTest(t *testing.T) {
+	expected := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath("M0,0 A10 5 10 0 1 10,0", expected); err != nil {
+		t.Fatal(err)
+	}
+	unrotated := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath("M0,0 A10 5 0 0 1 10,0", unrotated); err != nil {
+		t.Fatal(err)
+	}
+	if unrotated.Diff(expected.Commands()) == nil {
+		t.Fatal("expected the rotation to change the arc")
+	}
+	for _, input := range []string{"M0,0 A10 5 1e1 0 1 10,0", "M0,0 A10 5 1E+1 0 1 10,0", "M0,0 A10,5,1e1,0,1,10,0", "M0,0 A10 5 100e-1 01 10,0"} {
+		assertValidPathDeep(input, expected.Commands())
+		segments := mustParsePath(t, input)
+		if arc := segments[1]; arc.ArcAngle != 10 || arc.ArcLarge || !arc.ArcSweep {
+			t.Errorf("%q: expected a rotation of 10 with flags 0 1, got %v", input, arc)
+		}
+	}
+
+	// A malformed angle is reported rather than read as flags, and an
+	// exponent does not swallow the flags that follow it.
+	if err := WriteSvgPathDataToPath("M0,0 A10 5 1e 0 1 10,0", NewRecordingProxy()); !errors.Is(err, ErrMissingExponent) {
+		t.Errorf("expected ErrMissingExponent, got %v", err)
+	}
+	if err := WriteSvgPathDataToPath("M0,0 A10 5 1e01 10,0", NewRecordingProxy()); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("expected the flags after 1e01 to leave the y coordinate missing, got %v", err)
+	}
+}
+
+func TestDegenerateArcsAreBoundedAndFinite(t *testing.T) {
+	arc := func(start, radii, target PathOffset, angle float64) []PathSegmentData {
+		return []PathSegmentData{
+			{Command: SvgPathSegTypeMoveToAbs, TargetPoint: start},
+			{Command: SvgPathSegTypeArcToAbs, Point1: radii, TargetPoint: target, ArcAngle: angle, ArcLarge: true, ArcSweep: true},
+		}
+	}
+	paths := [][]PathSegmentData{
+		mustParsePath(t, "M0,0 A1e-37,1e-37 0 1 1 1e38,0"),
+		mustParsePath(t, "M0,0 A1e-30,1e30 45 1 0 10,10"),
+		arc(PathOffset{0, 0}, PathOffset{1e-300, 1e-300}, PathOffset{10, 0}, 0),
+		arc(PathOffset{0, 0}, PathOffset{1e300, 1e300}, PathOffset{1e-300, 0}, 0),
+		arc(PathOffset{1e300, 0}, PathOffset{1, 1}, PathOffset{-1e300, 1e300}, 30),
+		arc(PathOffset{0, 0}, PathOffset{1, 1}, PathOffset{10, 0}, math.Inf(1)),
+	}
+	for i, segments := range paths {
+		proxy := pointRecordingPathProxy{}
+		WriteSegmentsToPath(segments, &proxy)
+		if len(proxy.points) > 1+3*maxArcSegments {
+			t.Errorf("path %d: expected at most %d points, got %d", i, 1+3*maxArcSegments, len(proxy.points))
+		}
+		for _, p := range proxy.points {
+			if !isFiniteOffset(p) {
+				t.Errorf("path %d: non-finite point %v", i, p)
+			}
+		}
+	}
+}
+
+func TestSmoothCurveAfterSubpathBoundaryDeepTest(t *testing.T) {
+	// The first control point of an S starting a subpath is the current
+	// point, whether the previous subpath was closed or not.
+	for _, input := range []string{"M0,0 C1,5 2,5 3,0 Z M10,10 S20,20 30,30", "M0,0 C1,5 2,5 3,0 M10,10 S20,20 30,30"} {
+		proxy := NewRecordingProxy()
+		if err := WriteSvgPathDataToPath(input, proxy); err != nil {
+			t.Fatal(err)
+		}
+		if commands := proxy.Commands(); commands[len(commands)-1] != "cubicTo(10.0000, 10.0000, 20.0000, 20.0000, 30.0000, 30.0000)" {
+			t.Errorf("%q: expected the S to start at the new subpath's start, got %s", input, commands[len(commands)-1])
+		}
+	}
+
+	// Drawing on after a close starts at the closed subpath's start.
+	assertValidPathDeep("M0,0 C1,5 2,5 3,0 Z S20,20 30,30", []string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(1.0000, 5.0000, 2.0000, 5.0000, 3.0000, 0.0000)",
+		"close()",
+		"cubicTo(0.0000, 0.0000, 20.0000, 20.0000, 30.0000, 30.0000)",
+	})
+
+	// The same holds for T after a quadratic.
+	assertValidPathDeep("M0,0 Q5,5 6,0 M10,10 T40,10", []string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(3.3333, 3.3333, 5.3333, 3.3333, 6.0000, 0.0000)",
+		"moveTo(10.0000, 10.0000)",
+		"cubicTo(10.0000, 10.0000, 20.0000, 10.0000, 40.0000, 10.0000)",
+	})
+}
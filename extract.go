@@ -0,0 +1,110 @@
+package pathparsing
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// ExtractPathData returns the d attributes of the <path> elements in an SVG
+// document, in document order, ready for parsing. It is a lightweight scan
+// rather than an XML parser: it looks at the attributes of every <path> tag,
+// skips comments and CDATA sections, and unescapes character references in
+// the values, but does not check that the document is well formed. Paths
+// without a d attribute are left out. A <path> tag or attribute value that
+// is not terminated yields an error.
+func ExtractPathData(svgXML string) ([]string, error) {
+	var result []string
+	for i := 0; ; {
+		start := strings.IndexByte(svgXML[i:], '<')
+		if start < 0 {
+			return result, nil
+		}
+		i += start
+		rest := svgXML[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i = skipPast(svgXML, i, "-->")
+		case strings.HasPrefix(rest, "<![CDATA["):
+			i = skipPast(svgXML, i, "]]>")
+		case strings.HasPrefix(rest, "<path") && len(rest) > 5 && isTagNameEnd(rest[5]):
+			d, found, end, err := scanPathTag(svgXML, i+5)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				result = append(result, d)
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+}
+
+// skipPast returns the offset just after the first terminator at or after
+// i, or the end of s if there is none.
+func skipPast(s string, i int, terminator string) int {
+	if end := strings.Index(s[i:], terminator); end >= 0 {
+		return i + end + len(terminator)
+	}
+	return len(s)
+}
+
+// isTagNameEnd reports whether c may follow the name of an element.
+func isTagNameEnd(c byte) bool {
+	return isXMLWhitespace(c) || c == '/' || c == '>'
+}
+
+// isXMLWhitespace reports whether c is XML whitespace.
+func isXMLWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// scanPathTag scans the attributes of a <path> tag starting at offset i,
+// just after the element name. It returns the unescaped d attribute, whether
+// there was one, and the offset just after the tag.
+func scanPathTag(s string, i int) (d string, found bool, end int, err error) {
+	for {
+		for i < len(s) && isXMLWhitespace(s[i]) {
+			i++
+		}
+		switch {
+		case i >= len(s):
+			return "", false, 0, fmt.Errorf("unterminated <path> element at offset %d", i)
+		case s[i] == '>':
+			return d, found, i + 1, nil
+		case strings.HasPrefix(s[i:], "/>"):
+			return d, found, i + 2, nil
+		}
+
+		nameStart := i
+		for i < len(s) && s[i] != '=' && s[i] != '>' && s[i] != '/' && !isXMLWhitespace(s[i]) {
+			i++
+		}
+		name := s[nameStart:i]
+		for i < len(s) && isXMLWhitespace(s[i]) {
+			i++
+		}
+		if name == "" || i >= len(s) || s[i] != '=' {
+			return "", false, 0, fmt.Errorf("attribute %q of <path> element has no value at offset %d", name, nameStart)
+		}
+		i++
+		for i < len(s) && isXMLWhitespace(s[i]) {
+			i++
+		}
+		if i >= len(s) || (s[i] != '"' && s[i] != '\'') {
+			return "", false, 0, fmt.Errorf("attribute %q of <path> element is not quoted at offset %d", name, i)
+		}
+		quote := s[i]
+		valueEnd := strings.IndexByte(s[i+1:], quote)
+		if valueEnd < 0 {
+			return "", false, 0, fmt.Errorf("unterminated value of attribute %q at offset %d", name, i)
+		}
+		if name == "d" {
+			d = html.UnescapeString(s[i+1 : i+1+valueEnd])
+			found = true
+		}
+		i += valueEnd + 2
+	}
+}
@@ -0,0 +1,47 @@
+package pathparsing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractPathData(t *testing.T) {
+	const svg = `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24">
+  <!-- <path d="M9,9 H1"/> -->
+  <pathology d="M8,8"/>
+  <g id="layer">
+    <path id="first" d="M0,0 L10,0 Z" fill="red"/>
+    <path fill='none' d = 'M1,1
+      h5 &amp; v5'></path>
+    <path fill="blue"/>
+  </g>
+</svg>`
+	got, err := ExtractPathData(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"M0,0 L10,0 Z", "M1,1\n      h5 & v5"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if _, err := ParsePath(got[0]); err != nil {
+		t.Errorf("expected the extracted data to parse, got %v", err)
+	}
+}
+
+func TestExtractPathDataErrors(t *testing.T) {
+	for _, svg := range []string{
+		`<path d="M0,0`,
+		`<path d=M0,0/>`,
+		`<path d="M0,0"`,
+		`<path hidden/>`,
+	} {
+		if _, err := ExtractPathData(svg); err == nil {
+			t.Errorf("expected an error for %q", svg)
+		}
+	}
+	if got, err := ExtractPathData("<svg></svg>"); err != nil || got != nil {
+		t.Errorf("expected no paths, got %q, %v", got, err)
+	}
+}
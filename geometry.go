@@ -0,0 +1,951 @@
+package pathparsing
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DefaultFlattenTolerance is the maximum distance between a curve and the
+// polyline approximating it, used by geometry helpers that take no tolerance.
+const DefaultFlattenTolerance = 0.01
+
+// maxFlattenSegments bounds the number of line segments a single curve is
+// flattened into.
+const maxFlattenSegments = 10000
+
+// contour is a subpath flattened into a polyline.
+type contour struct {
+	points []PathOffset
+	closed bool
+}
+
+// flatteningPathProxy collects the commands written to it as contours,
+// replacing cubics with line segments.
+type flatteningPathProxy struct {
+	tolerance float64
+	contours  []contour
+	start     PathOffset
+	open      bool
+}
+
+func (p *flatteningPathProxy) MoveTo(x, y float64) {
+	p.start = PathOffset{x, y}
+	p.contours = append(p.contours, contour{points: []PathOffset{p.start}})
+	p.open = true
+}
+
+func (p *flatteningPathProxy) LineTo(x, y float64) {
+	p.addPoint(PathOffset{x, y})
+}
+
+func (p *flatteningPathProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	c := p.current()
+	flattenCubic(*c.last(), PathOffset{x1, y1}, PathOffset{x2, y2}, PathOffset{x3, y3}, p.tolerance, p.addPoint)
+}
+
+func (p *flatteningPathProxy) quadTo(x1, y1, x2, y2 float64) {
+	c := p.current()
+	flattenQuad(*c.last(), PathOffset{x1, y1}, PathOffset{x2, y2}, p.tolerance, p.addPoint)
+}
+
+func (p *flatteningPathProxy) Close() {
+	if p.open {
+		p.contours[len(p.contours)-1].closed = true
+		p.open = false
+	}
+}
+
+// current returns the contour being built, starting a new one at the start
+// of the previous subpath when drawing continues after a close.
+func (p *flatteningPathProxy) current() *contour {
+	if !p.open {
+		p.MoveTo(p.start.Dx, p.start.Dy)
+	}
+	return &p.contours[len(p.contours)-1]
+}
+
+// addPoint appends a point to the current contour.
+func (p *flatteningPathProxy) addPoint(point PathOffset) {
+	c := p.current()
+	c.points = append(c.points, point)
+}
+
+// last returns the last point of the contour.
+func (c *contour) last() *PathOffset {
+	return &c.points[len(c.points)-1]
+}
+
+// flattenContours normalizes the segments and flattens them into contours.
+func flattenContours(segments []PathSegmentData, tolerance float64) []contour {
+	proxy := flatteningPathProxy{tolerance: tolerance}
+	WriteSegmentsToPath(segments, &proxy)
+	return proxy.contours
+}
+
+// flattenCubic approximates the cubic Bézier p0..p3 with line segments no
+// further than tolerance from the curve, calling emit with every point after
+// p0. The segment count follows Wang's formula.
+func flattenCubic(p0, p1, p2, p3 PathOffset, tolerance float64, emit func(PathOffset)) {
+	dd := math.Max(
+		math.Hypot(p0.Dx-2*p1.Dx+p2.Dx, p0.Dy-2*p1.Dy+p2.Dy),
+		math.Hypot(p1.Dx-2*p2.Dx+p3.Dx, p1.Dy-2*p2.Dy+p3.Dy),
+	)
+	n := math.Ceil(math.Sqrt(0.75 * dd / tolerance))
+	segments := 1
+	if isFinite(n) && n > 1 {
+		segments = int(math.Min(n, maxFlattenSegments))
+	}
+	for i := 1; i < segments; i++ {
+		emit(EvalCubic(p0, p1, p2, p3, float64(i)/float64(segments)))
+	}
+	emit(p3)
+}
+
+// flattenQuad approximates the quadratic Bézier p0, c, p1 with line segments
+// no further than tolerance from the curve, calling emit with every point
+// after p0. The segment count follows Wang's formula for degree two, which
+// bounds the deviation of a quadratic exactly; it gives the same count as
+// flattenCubic does for the quadratic raised to a cubic, but the points are
+// evaluated on the quadratic itself.
+func flattenQuad(p0, c, p1 PathOffset, tolerance float64, emit func(PathOffset)) {
+	dd := math.Hypot(p0.Dx-2*c.Dx+p1.Dx, p0.Dy-2*c.Dy+p1.Dy)
+	n := math.Ceil(math.Sqrt(0.25 * dd / tolerance))
+	segments := 1
+	if isFinite(n) && n > 1 {
+		segments = int(math.Min(n, maxFlattenSegments))
+	}
+	for i := 1; i < segments; i++ {
+		emit(evalQuad(p0, c, p1, float64(i)/float64(segments)))
+	}
+	emit(p1)
+}
+
+// evalQuad returns the point at parameter t on the quadratic Bézier p0, c,
+// p1.
+func evalQuad(p0, c, p1 PathOffset, t float64) PathOffset {
+	mt := 1 - t
+	return PathOffset{
+		mt*mt*p0.Dx + 2*mt*t*c.Dx + t*t*p1.Dx,
+		mt*mt*p0.Dy + 2*mt*t*c.Dy + t*t*p1.Dy,
+	}
+}
+
+// polygonArea returns the signed area of the polygon, positive when it runs
+// clockwise in SVG's y-down coordinate system.
+func polygonArea(points []PathOffset) float64 {
+	area := 0.0
+	for i := range points {
+		j := (i + 1) % len(points)
+		area += points[i].Dx*points[j].Dy - points[j].Dx*points[i].Dy
+	}
+	return area / 2
+}
+
+// windingNumber returns how many times the closed polygon winds around p,
+// counting clockwise turns (in y-down coordinates) as positive.
+func windingNumber(points []PathOffset, p PathOffset) int {
+	winding := 0
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		cross := (b.Dx-a.Dx)*(p.Dy-a.Dy) - (p.Dx-a.Dx)*(b.Dy-a.Dy)
+		if a.Dy <= p.Dy {
+			if b.Dy > p.Dy && cross > 0 {
+				winding++
+			}
+		} else if b.Dy <= p.Dy && cross < 0 {
+			winding--
+		}
+	}
+	return winding
+}
+
+// isFilled reports whether a winding number is inside under the fill rule.
+func isFilled(winding int, evenOdd bool) bool {
+	if evenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// contourFillWeights returns, for every contour, whether the region it
+// encloses adds to (1), removes from (-1) or does not change (0) the filled
+// area. Contours are assumed not to cross each other, so each one is either
+// nested inside or disjoint from the others; the winding just outside a
+// contour is sampled at its first point and the winding just inside differs
+// from it by the contour's own direction.
+func contourFillWeights(contours []contour, evenOdd bool) []float64 {
+	weights := make([]float64, len(contours))
+	for i, c := range contours {
+		area := polygonArea(c.points)
+		if area == 0 {
+			continue
+		}
+		outside := 0
+		for j, other := range contours {
+			if j != i {
+				outside += windingNumber(other.points, c.points[0])
+			}
+		}
+		inside := outside + 1
+		if area < 0 {
+			inside = outside - 1
+		}
+		if isFilled(inside, evenOdd) {
+			weights[i]++
+		}
+		if isFilled(outside, evenOdd) {
+			weights[i]--
+		}
+	}
+	return weights
+}
+
+// SignedArea returns the signed area enclosed by the path, treating open
+// subpaths as implicitly closed like a fill does. The area is positive for
+// subpaths running clockwise in SVG's y-down coordinate system, so subpaths
+// of opposite direction cancel out.
+func SignedArea(segments []PathSegmentData) float64 {
+	area := 0.0
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		area += polygonArea(c.points)
+	}
+	return area
+}
+
+// Measure returns the length of the path and its signed area (see
+// SignedArea), flattened with the given tolerance in a single pass. The
+// length includes closing lines but not the jumps between subpaths, while the
+// area treats open subpaths as implicitly closed.
+func Measure(segments []PathSegmentData, tolerance float64) (perimeter, area float64) {
+	for _, c := range flattenContours(segments, tolerance) {
+		points := c.polyline()
+		for i := 1; i < len(points); i++ {
+			perimeter += math.Hypot(points[i].Dx-points[i-1].Dx, points[i].Dy-points[i-1].Dy)
+		}
+		area += polygonArea(c.points)
+	}
+	return perimeter, area
+}
+
+// Compactness returns 4π·area/perimeter² for the path measured with Measure
+// at the given tolerance, a shape classification metric that is 1 for a
+// circle and approaches 0 for thin or jagged shapes. The area's sign is
+// ignored, so the direction the path runs in does not matter. A path with
+// no length gives 0.
+func Compactness(segments []PathSegmentData, tolerance float64) float64 {
+	perimeter, area := Measure(segments, tolerance)
+	if perimeter == 0 {
+		return 0
+	}
+	return 4 * math.Pi * math.Abs(area) / (perimeter * perimeter)
+}
+
+// FilledArea returns the area painted when the path is filled with the
+// nonzero or, if evenOdd is set, the even-odd fill rule. Holes are
+// subtracted according to the fill rule, so a ring drawn with two contours
+// of the same direction has the area of the outer contour under nonzero and
+// the outer minus the inner area under even-odd. Subpaths are assumed not to
+// cross each other.
+func FilledArea(segments []PathSegmentData, evenOdd bool) float64 {
+	contours := flattenContours(segments, DefaultFlattenTolerance)
+	area := 0.0
+	for i, weight := range contourFillWeights(contours, evenOdd) {
+		area += weight * math.Abs(polygonArea(contours[i].points))
+	}
+	return area
+}
+
+// OffsetPolygon returns the closed polygon with every edge moved along its
+// normal by distance, outwards for a positive distance and inwards for a
+// negative one, regardless of the polygon's direction. Vertices are the
+// intersections of adjacent offset edges (miter joins). Repeated points are
+// dropped, and the result has one vertex per remaining input vertex.
+func OffsetPolygon(points []PathOffset, distance float64) []PathOffset {
+	polygon := cleanPolygon(points)
+	if len(polygon) < 3 {
+		return polygon
+	}
+
+	if polygonArea(polygon) < 0 {
+		distance = -distance
+	}
+	normals := make([]PathOffset, len(polygon))
+	for i, p := range polygon {
+		d := polygon[(i+1)%len(polygon)].Subtract(p)
+		length := math.Hypot(d.Dx, d.Dy)
+		normals[i] = PathOffset{d.Dy / length, -d.Dx / length}
+	}
+
+	result := make([]PathOffset, len(polygon))
+	for i, p := range polygon {
+		prev := normals[(i+len(polygon)-1)%len(polygon)]
+		next := normals[i]
+		dot := prev.Dx*next.Dx + prev.Dy*next.Dy
+		if 1+dot < 1e-9 {
+			result[i] = p.Add(next.Multiply(distance))
+			continue
+		}
+		result[i] = p.Add(prev.Add(next).Multiply(distance / (1 + dot)))
+	}
+	return result
+}
+
+// polygonCentroid returns the centroid of the polygon's area.
+func polygonCentroid(points []PathOffset) PathOffset {
+	var cx, cy, area float64
+	for i := range points {
+		j := (i + 1) % len(points)
+		c := points[i].Dx*points[j].Dy - points[j].Dx*points[i].Dy
+		cx += (points[i].Dx + points[j].Dx) * c
+		cy += (points[i].Dy + points[j].Dy) * c
+		area += c
+	}
+	return PathOffset{cx / (3 * area), cy / (3 * area)}
+}
+
+// Centroid returns the centroid of the area painted when the path is filled
+// with the nonzero fill rule, which is the visual center used for placing
+// labels. Holes pull the centroid away from them. If the path encloses no
+// area the zero offset is returned.
+func Centroid(segments []PathSegmentData) PathOffset {
+	contours := flattenContours(segments, DefaultFlattenTolerance)
+	var sum PathOffset
+	total := 0.0
+	for i, weight := range contourFillWeights(contours, false) {
+		if weight == 0 {
+			continue
+		}
+		area := weight * math.Abs(polygonArea(contours[i].points))
+		sum = sum.Add(polygonCentroid(contours[i].points).Multiply(area))
+		total += area
+	}
+	if total == 0 {
+		return ZeroPathOffset()
+	}
+	return sum.Multiply(1 / total)
+}
+
+// MomentOfInertia returns the polar moment of inertia of the area painted
+// when the path is filled with the nonzero fill rule, about its centroid and
+// for a uniform density per unit area, together with the centroid. Holes
+// are subtracted as in FilledArea. The path is flattened and each contour's
+// second moments come from the standard polygon formulas, then are moved to
+// the centroid with the parallel axis theorem. If the path encloses no area
+// zero and the zero offset are returned.
+func MomentOfInertia(segments []PathSegmentData, density float64) (float64, PathOffset) {
+	contours := flattenContours(segments, DefaultFlattenTolerance)
+	var sum PathOffset
+	total, moment := 0.0, 0.0
+	for i, weight := range contourFillWeights(contours, false) {
+		if weight == 0 {
+			continue
+		}
+		points := contours[i].points
+		area := polygonArea(points)
+		sum = sum.Add(polygonCentroid(points).Multiply(weight * math.Abs(area)))
+		total += weight * math.Abs(area)
+		moment += weight * math.Copysign(1, area) * polygonPolarMoment(points)
+	}
+	if total == 0 {
+		return 0, ZeroPathOffset()
+	}
+	centroid := sum.Multiply(1 / total)
+	moment -= total * (centroid.Dx*centroid.Dx + centroid.Dy*centroid.Dy)
+	return density * moment, centroid
+}
+
+// polygonPolarMoment returns the polar second moment of the polygon's area
+// about the origin, with the sign of polygonArea.
+func polygonPolarMoment(points []PathOffset) float64 {
+	moment := 0.0
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		c := a.Dx*b.Dy - b.Dx*a.Dy
+		moment += c * (a.Dx*a.Dx + a.Dx*b.Dx + b.Dx*b.Dx + a.Dy*a.Dy + a.Dy*b.Dy + b.Dy*b.Dy)
+	}
+	return moment / 12
+}
+
+// polyline returns the points of the contour, repeating the first point at
+// the end if the contour is closed so the closing edge is included.
+func (c contour) polyline() []PathOffset {
+	if c.closed && len(c.points) > 1 && c.points[0] != *c.last() {
+		return append(c.points[:len(c.points):len(c.points)], c.points[0])
+	}
+	return c.points
+}
+
+// lerp returns the point at parameter t on the line from a to b.
+func lerp(a, b PathOffset, t float64) PathOffset {
+	return a.Add(b.Subtract(a).Multiply(t))
+}
+
+// SubPath returns the part of the path between the arc lengths startLen and
+// endLen, measured along the flattened path from its start and summed over
+// its subpaths (including closing lines but not the jumps between subpaths).
+// The result consists of absolute moveTo and lineTo segments, with a new
+// moveTo for each subpath the range touches.
+func SubPath(segments []PathSegmentData, startLen, endLen float64) []PathSegmentData {
+	var result []PathSegmentData
+	distance := 0.0
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		points := c.polyline()
+		drawing := false
+		for i := 1; i < len(points) && distance < endLen; i++ {
+			a, b := points[i-1], points[i]
+			length := math.Hypot(b.Dx-a.Dx, b.Dy-a.Dy)
+			next := distance + length
+			if next > startLen && length > 0 {
+				if !drawing {
+					from := a
+					if startLen > distance {
+						from = lerp(a, b, (startLen-distance)/length)
+					}
+					result = append(result, PathSegmentData{Command: SvgPathSegTypeMoveToAbs, TargetPoint: from})
+					drawing = true
+				}
+				to := b
+				if endLen < next {
+					to = lerp(a, b, (endLen-distance)/length)
+				}
+				result = append(result, PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: to})
+			}
+			distance = next
+		}
+	}
+	return result
+}
+
+// ResampleByCount returns n points spread evenly by arc length along the
+// flattened path, from its start to its end, with lengths measured as in
+// SubPath. A path of zero length gives n copies of its first point, and n
+// below one or an empty path gives nil.
+func ResampleByCount(segments []PathSegmentData, n int) []PathOffset {
+	contours := flattenContours(segments, DefaultFlattenTolerance)
+	if n < 1 || len(contours) == 0 {
+		return nil
+	}
+
+	var edges [][2]PathOffset
+	total := 0.0
+	for _, c := range contours {
+		points := c.polyline()
+		for i := 1; i < len(points); i++ {
+			if points[i] != points[i-1] {
+				edges = append(edges, [2]PathOffset{points[i-1], points[i]})
+				total += math.Hypot(points[i].Dx-points[i-1].Dx, points[i].Dy-points[i-1].Dy)
+			}
+		}
+	}
+
+	result := make([]PathOffset, 0, n)
+	if len(edges) == 0 {
+		for len(result) < n {
+			result = append(result, contours[0].points[0])
+		}
+		return result
+	}
+	result = append(result, edges[0][0])
+	edge, distance := 0, 0.0
+	for i := 1; i < n; i++ {
+		target := total * float64(i) / float64(n-1)
+		for {
+			a, b := edges[edge][0], edges[edge][1]
+			length := math.Hypot(b.Dx-a.Dx, b.Dy-a.Dy)
+			if distance+length >= target || edge == len(edges)-1 {
+				result = append(result, lerp(a, b, math.Min(1, (target-distance)/length)))
+				break
+			}
+			distance += length
+			edge++
+		}
+	}
+	return result
+}
+
+// FeatureVector returns the path resampled to n points (see ResampleByCount)
+// as a flat vector x0, y0, x1, y1, ... of length 2n for comparing shapes,
+// for example in a classifier. The points are translated and uniformly
+// scaled so their bounding box touches the unit box at the origin with its
+// longer side of length one, which makes the vector independent of position
+// and size.
+func FeatureVector(segments []PathSegmentData, n int) []float64 {
+	points := ResampleByCount(segments, n)
+	if len(points) == 0 {
+		return nil
+	}
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		lo = PathOffset{math.Min(lo.Dx, p.Dx), math.Min(lo.Dy, p.Dy)}
+		hi = PathOffset{math.Max(hi.Dx, p.Dx), math.Max(hi.Dy, p.Dy)}
+	}
+	scale := 0.0
+	if extent := math.Max(hi.Dx-lo.Dx, hi.Dy-lo.Dy); extent > 0 {
+		scale = 1 / extent
+	}
+	vector := make([]float64, 0, 2*len(points))
+	for _, p := range points {
+		vector = append(vector, (p.Dx-lo.Dx)*scale, (p.Dy-lo.Dy)*scale)
+	}
+	return vector
+}
+
+// TangentHistogram returns the distribution of edge directions along the
+// flattened path, for telling round shapes from angular ones: bin i sums the
+// length of the edges whose direction lies within half a bin of angle
+// 2πi/bins, measured from the positive x axis towards positive y. Closing
+// lines count; a circle spreads its length evenly over the bins while a
+// rectangle puts it all into four. Fewer than one bin gives nil.
+func TangentHistogram(segments []PathSegmentData, bins int) []float64 {
+	if bins < 1 {
+		return nil
+	}
+	histogram := make([]float64, bins)
+	width := 2 * math.Pi / float64(bins)
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		points := c.polyline()
+		for i := 1; i < len(points); i++ {
+			d := points[i].Subtract(points[i-1])
+			length := math.Hypot(d.Dx, d.Dy)
+			if length == 0 {
+				continue
+			}
+			bin := int(math.Round(math.Atan2(d.Dy, d.Dx)/width)) % bins
+			if bin < 0 {
+				bin += bins
+			}
+			histogram[bin] += length
+		}
+	}
+	return histogram
+}
+
+// ProjectedExtent returns the range the path covers along the direction of
+// axis, as the smallest and largest dot products of its flattened points
+// with axis scaled to unit length, for separating axis tests when packing
+// shapes. A zero axis or a path without points gives zeros.
+func ProjectedExtent(segments []PathSegmentData, axis PathOffset) (lo, hi float64) {
+	axis = unitDirection(axis)
+	if axis == ZeroPathOffset() {
+		return 0, 0
+	}
+	return projectedRange(flattenedPoints(segments), axis)
+}
+
+// flattenedPoints returns the points of all contours of the path flattened
+// to DefaultFlattenTolerance.
+func flattenedPoints(segments []PathSegmentData) []PathOffset {
+	var points []PathOffset
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		points = append(points, c.points...)
+	}
+	return points
+}
+
+// projectedRange returns the smallest and largest dot products of the
+// points with axis, or zeros if there are no points.
+func projectedRange(points []PathOffset, axis PathOffset) (lo, hi float64) {
+	for i, p := range points {
+		v := p.Dx*axis.Dx + p.Dy*axis.Dy
+		if i == 0 {
+			lo, hi = v, v
+		}
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// ConvexHull returns the convex hull of the flattened path as a polygon
+// running clockwise in SVG's y-down coordinate system, so polygonArea is
+// positive, without repeating its first point. Points on the hull's edges
+// are left out. A path whose points are all collinear gives the two ends of
+// their line, a single point gives that point and an empty path nil.
+func ConvexHull(segments []PathSegmentData) []PathOffset {
+	points := flattenedPoints(segments)
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Dx != points[j].Dx {
+			return points[i].Dx < points[j].Dx
+		}
+		return points[i].Dy < points[j].Dy
+	})
+	if len(points) == 0 {
+		return nil
+	}
+	points = dedupePoints(points)
+	if len(points) < 3 {
+		return points
+	}
+
+	// Andrew's monotone chain: one chain from left to right, then the other
+	// back, each turning only one way.
+	hull := make([]PathOffset, 0, 2*len(points))
+	for pass := 0; pass < 2; pass++ {
+		chainStart := len(hull)
+		for _, p := range points {
+			for len(hull) >= chainStart+2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		// Each chain ends where the other starts.
+		hull = hull[:len(hull)-1]
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	return hull
+}
+
+// Collide reports whether the convex hulls of two paths overlap or touch,
+// using the separating axis theorem: the hulls are disjoint exactly when
+// their projections onto the normal of one of their edges do not overlap.
+// Since a hull covers its path, this is a conservative test that never
+// misses a collision but reports one for concave shapes whose hulls
+// overlap while the shapes do not. An empty path collides with nothing.
+func Collide(a, b []PathSegmentData) bool {
+	hullA, hullB := ConvexHull(a), ConvexHull(b)
+	if len(hullA) == 0 || len(hullB) == 0 {
+		return false
+	}
+	var axes []PathOffset
+	for _, hull := range [][]PathOffset{hullA, hullB} {
+		for i := range hull {
+			edge := unitDirection(hull[(i+1)%len(hull)].Subtract(hull[i]))
+			if edge == ZeroPathOffset() {
+				continue
+			}
+			axes = append(axes, PathOffset{-edge.Dy, edge.Dx})
+			if len(hull) < 3 {
+				// A line segment can also be separated along its length.
+				axes = append(axes, edge)
+			}
+		}
+	}
+	if len(axes) == 0 {
+		// Both hulls are single points.
+		return hullA[0] == hullB[0]
+	}
+	for _, axis := range axes {
+		minA, maxA := projectedRange(hullA, axis)
+		minB, maxB := projectedRange(hullB, axis)
+		if maxA < minB || maxB < minA {
+			return false
+		}
+	}
+	return true
+}
+
+// angleBetween returns the absolute angle in radians between two directions.
+func angleBetween(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 2*math.Pi)
+	if d > math.Pi {
+		d = 2*math.Pi - d
+	}
+	return d
+}
+
+// LongestStraightRun returns the arc length span of the longest nearly
+// straight stretch of the path, for example to place a label along it.
+// The path is flattened and a run continues for as long as each edge turns
+// no more than angleTol radians from the previous one; runs end at subpath
+// boundaries. Lengths are measured as in SubPath.
+func LongestStraightRun(segments []PathSegmentData, angleTol float64) (startLen, endLen float64) {
+	distance := 0.0
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		points := c.polyline()
+		runStart := distance
+		direction := math.NaN()
+		for i := 1; i < len(points); i++ {
+			d := points[i].Subtract(points[i-1])
+			length := math.Hypot(d.Dx, d.Dy)
+			if length == 0 {
+				continue
+			}
+			if !math.IsNaN(direction) && angleBetween(direction, d.Direction()) > angleTol {
+				runStart = distance
+			}
+			direction = d.Direction()
+			distance += length
+			if distance-runStart > endLen-startLen {
+				startLen, endLen = runStart, distance
+			}
+		}
+	}
+	return startLen, endLen
+}
+
+// ClosestPoint returns the point on the flattened outline of the path closest
+// to p, including closing lines, and its distance from p. A path drawing
+// nothing returns p at an infinite distance.
+func ClosestPoint(segments []PathSegmentData, p PathOffset) (PathOffset, float64) {
+	closest := p
+	best := math.Inf(1)
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		points := c.polyline()
+		for i := 1; i < len(points); i++ {
+			q := closestOnSegment(p, points[i-1], points[i])
+			if distance := math.Hypot(p.Dx-q.Dx, p.Dy-q.Dy); distance < best {
+				closest, best = q, distance
+			}
+		}
+	}
+	return closest, best
+}
+
+// closestOnSegment returns the point of the line segment from a to b that
+// is closest to p.
+func closestOnSegment(p, a, b PathOffset) PathOffset {
+	d := b.Subtract(a)
+	t := 0.0
+	if lengthSquared := d.Dx*d.Dx + d.Dy*d.Dy; lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, ((p.Dx-a.Dx)*d.Dx+(p.Dy-a.Dy)*d.Dy)/lengthSquared))
+	}
+	return lerp(a, b, t)
+}
+
+// Contains reports whether p lies in the area painted when the path is
+// filled with the nonzero or, if evenOdd is set, the even-odd fill rule.
+func Contains(segments []PathSegmentData, p PathOffset, evenOdd bool) bool {
+	winding := 0
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		winding += windingNumber(c.points, p)
+	}
+	return isFilled(winding, evenOdd)
+}
+
+// IntersectHorizontal returns the x coordinates, in increasing order, where
+// the horizontal line at y crosses the outline of the path, closing lines
+// included, for snapping to alignment guides. Cubics are split into
+// y-monotonic spans (see SplitCubicYMonotonic) and each crossing is solved
+// on the curve rather than on a flattened approximation. Each span counts
+// its lower end but not its upper one, as scanline fills do: a line through
+// a vertex where the outline keeps rising or falling is reported once, one
+// through a local minimum twice and one through a local maximum not at all,
+// so a closed outline always gives an even number of crossings and
+// alternate crossings bound its even-odd interior. Edges lying along the
+// line are not reported.
+func IntersectHorizontal(segments []PathSegmentData, y float64) []float64 {
+	return appendIntersections(nil, yMonotonicSpans(segments), y)
+}
+
+// ySpan is a piece of a path's outline along which y strictly increases or
+// decreases: a line, or a cubic split by SplitCubicYMonotonic.
+type ySpan struct {
+	points [4]PathOffset
+	cubic  bool
+}
+
+// yMonotonicSpans returns the non-horizontal lines, closing lines included,
+// and y-monotonic cubic spans of the normalized path, for finding many
+// horizontal crossings without splitting the path again for each.
+func yMonotonicSpans(segments []PathSegmentData) []ySpan {
+	var spans []ySpan
+	var current PathOffset
+	for _, seg := range normalizedSegments(segments) {
+		switch seg.Command {
+		case SvgPathSegTypeLineToAbs, SvgPathSegTypeClose:
+			if current.Dy != seg.TargetPoint.Dy {
+				spans = append(spans, ySpan{points: [4]PathOffset{current, {}, {}, seg.TargetPoint}})
+			}
+		case SvgPathSegTypeCubicToAbs:
+			for _, span := range SplitCubicYMonotonic(current, seg.Point1, seg.Point2, seg.TargetPoint) {
+				if span[0].Dy != span[3].Dy {
+					spans = append(spans, ySpan{points: span, cubic: true})
+				}
+			}
+		}
+		current = seg.TargetPoint
+	}
+	return spans
+}
+
+// appendIntersections appends the x coordinates where the horizontal line
+// at y crosses the spans to xs and sorts them, with the conventions of
+// IntersectHorizontal.
+func appendIntersections(xs []float64, spans []ySpan, y float64) []float64 {
+	for _, span := range spans {
+		a, b := span.points[0], span.points[3]
+		if y < math.Min(a.Dy, b.Dy) || y >= math.Max(a.Dy, b.Dy) {
+			continue
+		}
+		if span.cubic {
+			xs = append(xs, cubicAtY(span.points, y).Dx)
+		} else {
+			xs = append(xs, lerp(a, b, (y-a.Dy)/(b.Dy-a.Dy)).Dx)
+		}
+	}
+	sort.Float64s(xs)
+	return xs
+}
+
+// IntersectVertical returns the y coordinates, in increasing order, where
+// the vertical line at x crosses the outline of the path, as
+// IntersectHorizontal does for horizontal lines.
+func IntersectVertical(segments []PathSegmentData, x float64) []float64 {
+	return IntersectHorizontal(TransformSegments(segments, Affine{0, 1, 1, 0, 0, 0}), x)
+}
+
+// cubicAtY returns the point at height y on the y-monotonic cubic, found by
+// bisection.
+func cubicAtY(c [4]PathOffset, y float64) PathOffset {
+	lo, hi := 0.0, 1.0
+	rising := c[3].Dy > c[0].Dy
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if (EvalCubic(c[0], c[1], c[2], c[3], mid).Dy < y) == rising {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return EvalCubic(c[0], c[1], c[2], c[3], (lo+hi)/2)
+}
+
+// SignedDistance returns the distance from p to the outline of the path,
+// negative when p lies inside the area painted by the nonzero fill rule and
+// positive outside, as sampled into signed distance field textures.
+func SignedDistance(segments []PathSegmentData, p PathOffset) float64 {
+	_, distance := ClosestPoint(segments, p)
+	if Contains(segments, p, false) {
+		return -distance
+	}
+	return distance
+}
+
+// Edge is a line segment of a flattened path.
+type Edge struct {
+	A, B PathOffset
+	// SourceIndex is the index of the segment that produced the edge.
+	SourceIndex int
+}
+
+// edgePathProxy collects the commands written to it as edges, replacing
+// cubics with line segments and attributing them to the current source
+// segment.
+type edgePathProxy struct {
+	tolerance   float64
+	sourceIndex int
+	start       PathOffset
+	current     PathOffset
+	edges       []Edge
+}
+
+func (p *edgePathProxy) MoveTo(x, y float64) {
+	p.start = PathOffset{x, y}
+	p.current = p.start
+}
+
+func (p *edgePathProxy) LineTo(x, y float64) {
+	p.addPoint(PathOffset{x, y})
+}
+
+func (p *edgePathProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	flattenCubic(p.current, PathOffset{x1, y1}, PathOffset{x2, y2}, PathOffset{x3, y3}, p.tolerance, p.addPoint)
+}
+
+func (p *edgePathProxy) quadTo(x1, y1, x2, y2 float64) {
+	flattenQuad(p.current, PathOffset{x1, y1}, PathOffset{x2, y2}, p.tolerance, p.addPoint)
+}
+
+func (p *edgePathProxy) Close() {
+	p.addPoint(p.start)
+}
+
+// addPoint adds the edge from the current point to point, unless it has
+// zero length.
+func (p *edgePathProxy) addPoint(point PathOffset) {
+	if point != p.current {
+		p.edges = append(p.edges, Edge{p.current, point, p.sourceIndex})
+	}
+	p.current = point
+}
+
+// FlattenedEdges returns the edges of the path flattened with the given
+// tolerance, in drawing order and including closing lines, each annotated
+// with the index of the segment it comes from, for example to highlight the
+// command under the cursor in an editor. Zero-length edges are omitted.
+func FlattenedEdges(segments []PathSegmentData, tolerance float64) []Edge {
+	proxy := edgePathProxy{tolerance: tolerance}
+	normalizer := NewSvgPathNormalizer()
+	for i, seg := range segments {
+		proxy.sourceIndex = i
+		normalizer.emitSegment(seg, &proxy)
+	}
+	return proxy.edges
+}
+
+// circle is a circle given by its center and radius.
+type circle struct {
+	center PathOffset
+	radius float64
+}
+
+// contains reports whether p lies in the circle, allowing for rounding.
+func (c circle) contains(p PathOffset) bool {
+	return math.Hypot(p.Dx-c.center.Dx, p.Dy-c.center.Dy) <= c.radius*(1+1e-12)+1e-12
+}
+
+// circleThrough2 returns the smallest circle through a and b.
+func circleThrough2(a, b PathOffset) circle {
+	return circle{lerp(a, b, 0.5), math.Hypot(b.Dx-a.Dx, b.Dy-a.Dy) / 2}
+}
+
+// circleThrough3 returns the circle through a, b and c, or for collinear
+// points the smallest circle containing them.
+func circleThrough3(a, b, c PathOffset) circle {
+	bx, by := b.Dx-a.Dx, b.Dy-a.Dy
+	cx, cy := c.Dx-a.Dx, c.Dy-a.Dy
+	d := 2 * (bx*cy - by*cx)
+	if d == 0 {
+		best := circleThrough2(a, b)
+		for _, candidate := range []circle{circleThrough2(a, c), circleThrough2(b, c)} {
+			if candidate.radius > best.radius {
+				best = candidate
+			}
+		}
+		return best
+	}
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	center := PathOffset{(cy*b2 - by*c2) / d, (bx*c2 - cx*b2) / d}
+	return circle{center.Add(a), math.Hypot(center.Dx, center.Dy)}
+}
+
+// BoundingCircle returns the smallest circle enclosing the flattened path,
+// found with Welzl's algorithm over its points in a fixed shuffled order so
+// the result is deterministic. A path without segments gives a zero circle.
+func BoundingCircle(segments []PathSegmentData) (center PathOffset, radius float64) {
+	var points []PathOffset
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		points = append(points, c.points...)
+	}
+	if len(points) == 0 {
+		return ZeroPathOffset(), 0
+	}
+	random := rand.New(rand.NewSource(1))
+	random.Shuffle(len(points), func(i, j int) {
+		points[i], points[j] = points[j], points[i]
+	})
+
+	c := circle{points[0], 0}
+	for i := 1; i < len(points); i++ {
+		if c.contains(points[i]) {
+			continue
+		}
+		c = circle{points[i], 0}
+		for j := 0; j < i; j++ {
+			if c.contains(points[j]) {
+				continue
+			}
+			c = circleThrough2(points[i], points[j])
+			for k := 0; k < j; k++ {
+				if !c.contains(points[k]) {
+					c = circleThrough3(points[i], points[j], points[k])
+				}
+			}
+		}
+	}
+	return c.center, c.radius
+}
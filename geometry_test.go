@@ -0,0 +1,410 @@
+package pathparsing
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func mustParsePath(t *testing.T, svg string) []PathSegmentData {
+	t.Helper()
+	segments, err := ParsePath(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return segments
+}
+
+func assertClose(t *testing.T, name string, expected, actual, tolerance float64) {
+	t.Helper()
+	if math.Abs(expected-actual) > tolerance {
+		t.Errorf("%s: expected %v, got %v", name, expected, actual)
+	}
+}
+
+func TestSignedArea(t *testing.T) {
+	assertClose(t, "clockwise", 100, SignedArea(mustParsePath(t, "M0,0 H10 V10 H0 Z")), 1e-9)
+	assertClose(t, "counter-clockwise", -100, SignedArea(mustParsePath(t, "M0,0 V10 H10 V0 Z")), 1e-9)
+	assertClose(t, "circle", math.Pi*25, SignedArea(mustParsePath(t, "M0,5 A5,5 0 0 1 10,5 A5,5 0 0 1 0,5 Z")), 0.5)
+}
+
+func TestMeasure(t *testing.T) {
+	perimeter, area := Measure(mustParsePath(t, "M0,0 H1 V1 H0 Z"), DefaultFlattenTolerance)
+	assertClose(t, "square perimeter", 4, perimeter, 1e-9)
+	assertClose(t, "square area", 1, math.Abs(area), 1e-9)
+
+	perimeter, area = Measure(mustParsePath(t, "M0,5 A5,5 0 0 1 10,5 A5,5 0 0 1 0,5 Z M20,0 h3"), DefaultFlattenTolerance)
+	assertClose(t, "circle perimeter", 10*math.Pi+3, perimeter, 0.1)
+	assertClose(t, "circle area", 25*math.Pi, area, 0.5)
+}
+
+func TestFilledArea(t *testing.T) {
+	sameDirection := mustParsePath(t, "M0,0 H10 V10 H0 Z M3,3 H7 V7 H3 Z")
+	oppositeDirection := mustParsePath(t, "M0,0 H10 V10 H0 Z M3,3 V7 H7 V3 Z")
+
+	assertClose(t, "even-odd donut", 84, FilledArea(sameDirection, true), 1e-9)
+	assertClose(t, "nonzero donut", 84, FilledArea(oppositeDirection, false), 1e-9)
+	assertClose(t, "even-odd opposite donut", 84, FilledArea(oppositeDirection, true), 1e-9)
+	assertClose(t, "nonzero filled hole", 100, FilledArea(sameDirection, false), 1e-9)
+	assertClose(t, "disjoint", 8, FilledArea(mustParsePath(t, "M0,0 H2 V2 H0 Z M5,5 V7 H7 V5 Z"), false), 1e-9)
+}
+
+func TestOffsetPolygon(t *testing.T) {
+	square := []PathOffset{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	inset := []PathOffset{{0.1, 0.1}, {0.9, 0.1}, {0.9, 0.9}, {0.1, 0.9}}
+	assertPointsClose(t, inset, OffsetPolygon(square, -0.1), 1e-9)
+
+	reversed := []PathOffset{{0, 1}, {1, 1}, {1, 0}, {0, 0}}
+	outset := []PathOffset{{-0.1, 1.1}, {1.1, 1.1}, {1.1, -0.1}, {-0.1, -0.1}}
+	assertPointsClose(t, outset, OffsetPolygon(reversed, 0.1), 1e-9)
+
+	withDuplicate := []PathOffset{{0, 0}, {1, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}
+	assertPointsClose(t, inset, OffsetPolygon(withDuplicate, -0.1), 1e-9)
+}
+
+func TestCentroid(t *testing.T) {
+	tests := []struct {
+		input    string
+		centroid PathOffset
+	}{
+		{"M-5,-5 H5 V5 H-5 Z", PathOffset{0, 0}},
+		{"M0,0 H10 V4 H4 V10 H0 Z", PathOffset{3.875, 3.875}},
+		{"M0,0 H10 V10 H0 Z M1,1 V5 H5 V1 Z", PathOffset{452.0 / 84, 452.0 / 84}},
+	}
+	for _, test := range tests {
+		assertPointsClose(t, []PathOffset{test.centroid}, []PathOffset{Centroid(mustParsePath(t, test.input))}, 1e-9)
+	}
+}
+
+func TestMomentOfInertia(t *testing.T) {
+	moment, centroid := MomentOfInertia(mustParsePath(t, "M10,20 h10 v10 h-10 Z"), 2)
+	assertClose(t, "square moment", 2*10000.0/6, moment, 1e-6)
+	assertPointsClose(t, []PathOffset{{15, 25}}, []PathOffset{centroid}, 1e-9)
+
+	moment, centroid = MomentOfInertia(mustParsePath(t, "M0,0 V10 H10 V0 Z M4,4 H6 V6 H4 Z"), 1)
+	assertClose(t, "square with hole moment", (10000.0-16)/6, moment, 1e-6)
+	assertPointsClose(t, []PathOffset{{5, 5}}, []PathOffset{centroid}, 1e-9)
+
+	moment, _ = MomentOfInertia(mustParsePath(t, "M0,0 A5,5 0 0 1 10,0 A5,5 0 0 1 0,0 Z"), 1)
+	assertClose(t, "circle moment", math.Pi*625/2, moment, 5)
+
+	if moment, centroid := MomentOfInertia(mustParsePath(t, "M0,0 H10"), 1); moment != 0 || centroid != ZeroPathOffset() {
+		t.Errorf("expected no moment for a line, got %v at %v", moment, centroid)
+	}
+}
+
+func TestSubPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		start    float64
+		end      float64
+		expected string
+	}{
+		{"M0,0 L10,0", 2.5, 7.5, "M2.5,0 L7.5,0"},
+		{"M0,0 L10,0 L10,10", 5, 15, "M5,0 L10,0 L10,5"},
+		{"M0,0 H10 V10 H0 Z", 35, 40, "M0,5 L0,0"},
+		{"M0,0 H10 M20,0 H30", 8, 12, "M8,0 L10,0 M20,0 L22,0"},
+		{"M0,0 H10", 20, 30, ""},
+	}
+	for _, test := range tests {
+		expected := AbsoluteSegments(mustParsePath(t, test.expected))
+		actual := SubPath(mustParsePath(t, test.input), test.start, test.end)
+		if len(expected) != len(actual) {
+			t.Errorf("%q: expected %v, got %v", test.input, expected, actual)
+			continue
+		}
+		for i := range expected {
+			assertPointsClose(t, []PathOffset{expected[i].TargetPoint}, []PathOffset{actual[i].TargetPoint}, 1e-9)
+			if expected[i].Command != actual[i].Command {
+				t.Errorf("%q: segment %d: expected %v, got %v", test.input, i, expected[i], actual[i])
+			}
+		}
+	}
+}
+
+func TestLongestStraightRun(t *testing.T) {
+	tests := []struct {
+		input      string
+		start, end float64
+	}{
+		{"M0,0 H10 V4", 0, 10},
+		{"M0,0 V4 H10", 4, 14},
+		{"M0,0 H3 L6,0.01 L9,0 V20", 9.00001, 29.00001},
+		{"M0,0 H5 M0,10 H3 H8 V11", 5, 13},
+	}
+	for _, test := range tests {
+		start, end := LongestStraightRun(mustParsePath(t, test.input), 0.01)
+		assertClose(t, test.input+" start", test.start, start, 1e-4)
+		assertClose(t, test.input+" end", test.end, end, 1e-4)
+	}
+}
+
+func TestSignedDistance(t *testing.T) {
+	square := mustParsePath(t, "M0,0 H10 V10 H0 Z")
+	assertClose(t, "center", -5, SignedDistance(square, PathOffset{5, 5}), 1e-9)
+	assertClose(t, "inside near edge", -1, SignedDistance(square, PathOffset{5, 9}), 1e-9)
+	assertClose(t, "outside", 5, SignedDistance(square, PathOffset{15, 5}), 1e-9)
+	assertClose(t, "corner", math.Sqrt2, SignedDistance(square, PathOffset{11, -1}), 1e-9)
+
+	closest, _ := ClosestPoint(square, PathOffset{12, 4})
+	if closest != (PathOffset{10, 4}) {
+		t.Errorf("expected closest point (10, 4), got %v", closest)
+	}
+	if !Contains(square, PathOffset{5, 5}, true) || Contains(square, PathOffset{-1, 5}, false) {
+		t.Error("unexpected containment")
+	}
+}
+
+func TestFlattenedEdges(t *testing.T) {
+	cubic := []PathSegmentData{{Command: SvgPathSegTypeCubicToAbs, Point1: PathOffset{0, 10}, Point2: PathOffset{10, 10}, TargetPoint: PathOffset{10, 0}}}
+	edges := FlattenedEdges(cubic, DefaultFlattenTolerance)
+	if len(edges) < 2 {
+		t.Fatalf("expected the cubic to be flattened, got %v", edges)
+	}
+	for i, edge := range edges {
+		if edge.SourceIndex != 0 {
+			t.Errorf("edge %d: expected source index 0, got %d", i, edge.SourceIndex)
+		}
+		if i > 0 && edge.A != edges[i-1].B {
+			t.Errorf("edge %d: does not continue from the previous edge", i)
+		}
+	}
+	if edges[len(edges)-1].B != (PathOffset{10, 0}) {
+		t.Errorf("expected the last edge to end at (10, 0), got %v", edges[len(edges)-1].B)
+	}
+
+	edges = FlattenedEdges(mustParsePath(t, "M0,0 h10 v10 z"), DefaultFlattenTolerance)
+	expected := []Edge{
+		{PathOffset{0, 0}, PathOffset{10, 0}, 1},
+		{PathOffset{10, 0}, PathOffset{10, 10}, 2},
+		{PathOffset{10, 10}, PathOffset{0, 0}, 3},
+	}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Errorf("expected %v, got %v", expected, edges)
+	}
+}
+
+func TestBoundingCircle(t *testing.T) {
+	center, radius := BoundingCircle(mustParsePath(t, "M0,0 L10,0 L5,1 L3,2 L7,-2 Z"))
+	assertClose(t, "segment center x", 5, center.Dx, 1e-9)
+	assertClose(t, "segment center y", 0, center.Dy, 1e-9)
+	assertClose(t, "segment radius", 5, radius, 1e-9)
+
+	center, radius = BoundingCircle(mustParsePath(t, "M0,0 L4,0 L2,3.4641016151377544 Z M2,1 L2.5,1.5"))
+	assertClose(t, "triangle center x", 2, center.Dx, 1e-9)
+	assertClose(t, "triangle center y", 3.4641016151377544/3, center.Dy, 1e-9)
+	assertClose(t, "triangle radius", 4/math.Sqrt(3), radius, 1e-9)
+
+	center, radius = BoundingCircle(CircleToSegments(3, 4, 5))
+	assertClose(t, "circle center x", 3, center.Dx, 0.01)
+	assertClose(t, "circle center y", 4, center.Dy, 0.01)
+	assertClose(t, "circle radius", 5, radius, 0.01)
+}
+
+func TestResampleByCount(t *testing.T) {
+	points := ResampleByCount(mustParsePath(t, "M0,0 H10 V10 M20,0 h10"), 7)
+	expected := []PathOffset{{0, 0}, {5, 0}, {10, 0}, {10, 5}, {10, 10}, {25, 0}, {30, 0}}
+	assertPointsClose(t, expected, points, 1e-9)
+	if ResampleByCount(nil, 3) != nil || ResampleByCount(mustParsePath(t, "M0,0 H1"), 0) != nil {
+		t.Error("expected nil")
+	}
+}
+
+func TestFeatureVector(t *testing.T) {
+	shape := mustParsePath(t, "M0,0 C10,20 30,-10 40,10 L20,30 Z")
+	moved := TransformSegments(shape, TranslateAffine(100, -50).Multiply(ScaleAffine(3, 3)))
+	a, b := FeatureVector(shape, 32), FeatureVector(moved, 32)
+	if len(a) != 64 || len(b) != 64 {
+		t.Fatalf("expected 64 values, got %d and %d", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] < 0 || a[i] > 1 {
+			t.Errorf("value %d outside the unit box: %v", i, a[i])
+		}
+		assertClose(t, fmt.Sprint("value ", i), a[i], b[i], 1e-3)
+	}
+}
+
+func TestTangentHistogram(t *testing.T) {
+	histogram := TangentHistogram(mustParsePath(t, "M0,0 H10 V10 H0 Z"), 36)
+	total := 0.0
+	for _, weight := range histogram {
+		total += weight
+	}
+	for _, bin := range []int{0, 9, 18, 27} {
+		assertClose(t, "square edge", 10, histogram[bin], 1e-9)
+	}
+	assertClose(t, "square total", 40, total, 1e-9)
+
+	// A circle spreads its circumference evenly.
+	histogram = TangentHistogram(mustParsePath(t, "M10,0 A10,10 0 1 1 -10,0 A10,10 0 1 1 10,0 Z"), 4)
+	for i, weight := range histogram {
+		assertClose(t, fmt.Sprintf("circle bin %d", i), 2*math.Pi*10/4, weight, 0.5)
+	}
+	if TangentHistogram(nil, 0) != nil {
+		t.Error("expected nil without bins")
+	}
+}
+
+func TestIntersectHorizontal(t *testing.T) {
+	circle := mustParsePath(t, "M10,0 A10,10 0 1 1 -10,0 A10,10 0 1 1 10,0 Z")
+	xs := IntersectHorizontal(circle, 6)
+	if len(xs) != 2 {
+		t.Fatalf("expected two intersections, got %v", xs)
+	}
+	// The arcs become cubics, which stray from the circle by less than 0.03%.
+	assertClose(t, "left", -8, xs[0], 0.01)
+	assertClose(t, "right", 8, xs[1], 0.01)
+	if xs := IntersectHorizontal(circle, 11); len(xs) != 0 {
+		t.Errorf("expected no intersections above the circle, got %v", xs)
+	}
+
+	// A vertex the outline passes through is reported once, a local
+	// minimum twice and a local maximum not at all.
+	if xs := IntersectHorizontal(mustParsePath(t, "M0,0 L10,10 L0,20"), 10); !reflect.DeepEqual(xs, []float64{10}) {
+		t.Errorf("unexpected intersections through a vertex %v", xs)
+	}
+	if xs := IntersectHorizontal(mustParsePath(t, "M0,10 L10,0 L20,10 Z"), 0); !reflect.DeepEqual(xs, []float64{10, 10}) {
+		t.Errorf("unexpected intersections at a minimum %v", xs)
+	}
+	if xs := IntersectHorizontal(mustParsePath(t, "M0,0 L10,10 L20,0 Z"), 10); len(xs) != 0 {
+		t.Errorf("unexpected intersections at a maximum %v", xs)
+	}
+
+	// The closing line counts and an edge along the line is skipped.
+	if xs := IntersectHorizontal(mustParsePath(t, "M0,0 L10,10 L20,10 Z"), 5); !reflect.DeepEqual(xs, []float64{5, 10}) {
+		t.Errorf("unexpected intersections with the closing line %v", xs)
+	}
+	if xs := IntersectHorizontal(mustParsePath(t, "M0,0 L5,5 L10,0 Z"), 0); !reflect.DeepEqual(xs, []float64{0, 10}) {
+		t.Errorf("unexpected intersections at the base %v", xs)
+	}
+	if ys := IntersectVertical(mustParsePath(t, "M0,0 L10,10 L20,0 Z"), 5); !reflect.DeepEqual(ys, []float64{0, 5}) {
+		t.Errorf("unexpected vertical intersections %v", ys)
+	}
+}
+
+func TestFlattenQuad(t *testing.T) {
+	p0, c, p1 := PathOffset{0, 0}, PathOffset{50, 100}, PathOffset{100, 0}
+	tolerance := 0.01
+	var native, viaCubic []PathOffset
+	flattenQuad(p0, c, p1, tolerance, func(p PathOffset) { native = append(native, p) })
+	flattenCubic(p0, lerp(p0, c, 2.0/3), lerp(p1, c, 2.0/3), p1, tolerance, func(p PathOffset) { viaCubic = append(viaCubic, p) })
+	if len(native) > len(viaCubic) {
+		t.Fatalf("expected no more points natively, got %d and %d via a cubic", len(native), len(viaCubic))
+	}
+	for i := range native {
+		if d := native[i].Subtract(viaCubic[i]); math.Hypot(d.Dx, d.Dy) > 1e-9 {
+			t.Errorf("point %d: %v natively, %v via a cubic", i, native[i], viaCubic[i])
+		}
+	}
+
+	// The midpoint of the curve between two neighbouring points lies within
+	// the tolerance of the line joining them.
+	n := len(native)
+	points := append([]PathOffset{p0}, native...)
+	for i := 1; i < len(points); i++ {
+		mid := evalQuad(p0, c, p1, (float64(i)-0.5)/float64(n))
+		q := closestOnSegment(mid, points[i-1], points[i])
+		if distance := math.Hypot(mid.Dx-q.Dx, mid.Dy-q.Dy); distance > tolerance {
+			t.Errorf("edge %d strays %v from the curve", i, distance)
+		}
+	}
+
+	// Quadratics reach the geometry helpers without conversion to cubics.
+	contours := flattenContours(mustParsePath(t, "M0,0 Q50,100 100,0"), tolerance)
+	if len(contours) != 1 || len(contours[0].points) != 1+n {
+		t.Errorf("expected the quad flattened natively into %d points, got %v", 1+n, contours)
+	}
+}
+
+// quadMethodPathProxy is a caller's PathProxy that happens to have a QuadTo
+// method of its own.
+type quadMethodPathProxy struct {
+	*RecordingProxy
+	quads int
+}
+
+func (p *quadMethodPathProxy) QuadTo(x1, y1, x2, y2 float64) {
+	p.quads++
+}
+
+func TestQuadsReachOtherProxiesAsCubics(t *testing.T) {
+	proxy := &quadMethodPathProxy{RecordingProxy: NewRecordingProxy()}
+	if err := WriteSvgPathDataToPath("M0,0 Q3,3 6,0", proxy); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"moveTo(0.0000, 0.0000)", "cubicTo(2.0000, 2.0000, 4.0000, 2.0000, 6.0000, 0.0000)"}
+	if err := proxy.Diff(expected); err != nil || proxy.quads != 0 {
+		t.Errorf("expected the quad as a cubic and no QuadTo calls, got %d QuadTo calls: %v", proxy.quads, err)
+	}
+}
+
+func TestProjectedExtent(t *testing.T) {
+	square := mustParsePath(t, "M0,0 H10 V10 H0 Z")
+	lo, hi := ProjectedExtent(square, PathOffset{1, 1})
+	assertClose(t, "min", 0, lo, 1e-9)
+	assertClose(t, "max", 10*math.Sqrt2, hi, 1e-9)
+
+	lo, hi = ProjectedExtent(mustParsePath(t, "M5,5 h10 v10 h-10 Z"), PathOffset{0, -3})
+	assertClose(t, "min", -15, lo, 1e-9)
+	assertClose(t, "max", -5, hi, 1e-9)
+
+	if lo, hi := ProjectedExtent(square, PathOffset{}); lo != 0 || hi != 0 {
+		t.Errorf("expected zeros for a zero axis, got %v, %v", lo, hi)
+	}
+}
+
+func TestConvexHull(t *testing.T) {
+	hull := ConvexHull(mustParsePath(t, "M0,0 L10,0 L5,2 L10,10 L5,10 L0,10 Z"))
+	expected := []PathOffset{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	if !reflect.DeepEqual(hull, expected) {
+		t.Errorf("expected %v, got %v", expected, hull)
+	}
+	if area := polygonArea(hull); area <= 0 {
+		t.Errorf("expected a clockwise hull, got area %v", area)
+	}
+	if hull := ConvexHull(mustParsePath(t, "M0,0 L5,5 L10,10 L2,2")); !reflect.DeepEqual(hull, []PathOffset{{0, 0}, {10, 10}}) {
+		t.Errorf("expected the ends of a line, got %v", hull)
+	}
+	if hull := ConvexHull(nil); hull != nil {
+		t.Errorf("expected no hull, got %v", hull)
+	}
+}
+
+func TestCollide(t *testing.T) {
+	square := mustParsePath(t, "M0,0 H10 V10 H0 Z")
+	tests := []struct {
+		other    string
+		expected bool
+	}{
+		{"M5,5 L15,5 L15,15 Z", true},
+		{"M10,10 H20 V20 H10 Z", true},
+		{"M20,0 H30 V10 H20 Z", false},
+		// Only a diagonal separates this triangle from the square.
+		{"M12,9 L9,12 L20,20 Z", false},
+		{"M2,2 L3,3", true},
+		{"M11,0 L20,0", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if actual := Collide(square, mustParsePath(t, test.other)); actual != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.other, test.expected, actual)
+		}
+	}
+	if !Collide(mustParsePath(t, "M0,0 L10,0"), mustParsePath(t, "M5,0 L20,0")) || Collide(mustParsePath(t, "M0,0 L10,0"), mustParsePath(t, "M11,0 L20,0")) {
+		t.Error("expected collinear segments to collide only where they overlap")
+	}
+}
+
+func TestCompactness(t *testing.T) {
+	circle := mustParsePath(t, "M10,0 A10,10 0 1 1 -10,0 A10,10 0 1 1 10,0 Z")
+	assertClose(t, "circle", 1, Compactness(circle, 0.001), 0.001)
+	assertClose(t, "square", math.Pi/4, Compactness(mustParsePath(t, "M0,0 h10 v10 h-10 Z"), 0.01), 1e-9)
+	if thin := Compactness(mustParsePath(t, "M0,0 H100 V1 H0 Z"), 0.01); thin > 0.1 {
+		t.Errorf("expected a thin rectangle to score much lower, got %v", thin)
+	}
+	if c := Compactness(mustParsePath(t, "M5,5"), 0.01); c != 0 {
+		t.Errorf("expected 0 for a path without length, got %v", c)
+	}
+}
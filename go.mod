@@ -2,4 +2,7 @@ module github.com/ahmed-hararaa/pathparsing
 
 go 1.23.6
 
-require github.com/go-gl/mathgl v1.2.0 // indirect
+require (
+	github.com/go-gl/mathgl v1.2.0
+	golang.org/x/image v0.25.0
+)
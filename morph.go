@@ -0,0 +1,172 @@
+package pathparsing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// segmentPathProxy records the commands written to it as absolute moveTo,
+// lineTo, cubicTo and close segments.
+type segmentPathProxy struct {
+	segments []PathSegmentData
+	start    PathOffset
+}
+
+func (p *segmentPathProxy) MoveTo(x, y float64) {
+	p.start = PathOffset{x, y}
+	p.segments = append(p.segments, PathSegmentData{Command: SvgPathSegTypeMoveToAbs, TargetPoint: p.start})
+}
+
+func (p *segmentPathProxy) LineTo(x, y float64) {
+	p.segments = append(p.segments, PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: PathOffset{x, y}})
+}
+
+func (p *segmentPathProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.segments = append(p.segments, PathSegmentData{
+		Command:     SvgPathSegTypeCubicToAbs,
+		Point1:      PathOffset{x1, y1},
+		Point2:      PathOffset{x2, y2},
+		TargetPoint: PathOffset{x3, y3},
+	})
+}
+
+func (p *segmentPathProxy) Close() {
+	p.segments = append(p.segments, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: p.start})
+}
+
+// normalizedSegments returns the segments as WriteSegmentsToPath writes
+// them: absolute moveTos, lineTos, cubics and closes, with quadratics and
+// arcs turned into cubics.
+func normalizedSegments(segments []PathSegmentData) []PathSegmentData {
+	proxy := segmentPathProxy{}
+	WriteSegmentsToPath(segments, &proxy)
+	return proxy.segments
+}
+
+// ErrIncompatiblePaths is wrapped by the error MorphPaths returns for paths
+// whose normalized commands differ.
+var ErrIncompatiblePaths = errors.New("paths have different command structures")
+
+// MorphPaths interpolates between two paths for animation, returning the
+// path at parameter t: a at 0, b at 1 and the coordinates in between
+// linearly interpolated, or extrapolated for t outside [0, 1]. Both paths
+// are normalized first, so quadratics and arcs become cubics and the result
+// consists of absolute moveTo, lineTo, cubicTo and close segments. The
+// normalized paths must have the same sequence of commands; otherwise an
+// error wrapping ErrIncompatiblePaths names the first difference.
+func MorphPaths(a, b []PathSegmentData, t float64) ([]PathSegmentData, error) {
+	from := normalizedSegments(a)
+	to := normalizedSegments(b)
+	if len(from) != len(to) {
+		return nil, fmt.Errorf("%w: %d segments and %d segments", ErrIncompatiblePaths, len(from), len(to))
+	}
+	result := make([]PathSegmentData, len(from))
+	for i := range from {
+		if from[i].Command != to[i].Command {
+			return nil, fmt.Errorf("%w: segment %d is %c in one path and %c in the other",
+				ErrIncompatiblePaths, i, segmentLetters[from[i].Command], segmentLetters[to[i].Command])
+		}
+		result[i] = PathSegmentData{
+			Command:     from[i].Command,
+			TargetPoint: lerp(from[i].TargetPoint, to[i].TargetPoint, t),
+			Point1:      lerp(from[i].Point1, to[i].Point1, t),
+			Point2:      lerp(from[i].Point2, to[i].Point2, t),
+		}
+	}
+	return result, nil
+}
+
+// MakeCompatible resamples two paths so that MorphPaths can interpolate
+// between them. Both paths are flattened and their subpaths paired in
+// order; where one path has fewer subpaths it is padded with subpaths
+// collapsed to its last point, so shapes appear from or shrink into a
+// point. Each pair is resampled evenly by arc length to the same number of
+// points, the larger of the two flattened point counts, and closed only if
+// both are closed; an open subpath paired with a closed one runs around the
+// closed one's outline back to its start. The results consist of absolute
+// moveTo, lineTo and close segments with identical command structures.
+func MakeCompatible(a, b []PathSegmentData) (a2, b2 []PathSegmentData) {
+	ca := flattenContours(a, DefaultFlattenTolerance)
+	cb := flattenContours(b, DefaultFlattenTolerance)
+	if len(ca) == 0 && len(cb) == 0 {
+		return nil, nil
+	}
+	ca = padContours(ca, cb)
+	cb = padContours(cb, ca)
+	for i := range ca {
+		pa, pb := ca[i].polyline(), cb[i].polyline()
+		closed := ca[i].closed && cb[i].closed
+		n := max(len(pa), len(pb), 2)
+		a2 = appendResampled(a2, resamplePolyline(pa, n), closed)
+		b2 = appendResampled(b2, resamplePolyline(pb, n), closed)
+	}
+	return a2, b2
+}
+
+// padContours returns the contours with contours collapsed to the last
+// point added until there are as many as in other.
+func padContours(contours, other []contour) []contour {
+	point := ZeroPathOffset()
+	if len(contours) > 0 {
+		point = *contours[len(contours)-1].last()
+	}
+	for i := len(contours); i < len(other); i++ {
+		contours = append(contours, contour{points: []PathOffset{point}, closed: other[i].closed})
+	}
+	return contours
+}
+
+// resamplePolyline returns n >= 2 points spread evenly by arc length along
+// the polyline, including both of its ends. A single point is repeated.
+func resamplePolyline(points []PathOffset, n int) []PathOffset {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += math.Hypot(points[i].Dx-points[i-1].Dx, points[i].Dy-points[i-1].Dy)
+	}
+	result := make([]PathOffset, 0, n)
+	result = append(result, points[0])
+	if len(points) == 1 {
+		for len(result) < n {
+			result = append(result, points[0])
+		}
+		return result
+	}
+	edge, distance := 1, 0.0
+	for i := 1; i < n; i++ {
+		target := total * float64(i) / float64(n-1)
+		for edge < len(points)-1 {
+			length := math.Hypot(points[edge].Dx-points[edge-1].Dx, points[edge].Dy-points[edge-1].Dy)
+			if distance+length >= target {
+				break
+			}
+			distance += length
+			edge++
+		}
+		a, b := points[edge-1], points[edge]
+		length := math.Hypot(b.Dx-a.Dx, b.Dy-a.Dy)
+		t := 1.0
+		if length > 0 {
+			t = math.Min(1, (target-distance)/length)
+		}
+		result = append(result, lerp(a, b, t))
+	}
+	return result
+}
+
+// appendResampled appends a subpath through the points, closing it instead
+// of drawing the last point, which is back at the start, if closed.
+func appendResampled(segments []PathSegmentData, points []PathOffset, closed bool) []PathSegmentData {
+	segments = append(segments, PathSegmentData{Command: SvgPathSegTypeMoveToAbs, TargetPoint: points[0]})
+	last := len(points)
+	if closed {
+		last--
+	}
+	for _, p := range points[1:last] {
+		segments = append(segments, PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: p})
+	}
+	if closed {
+		segments = append(segments, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: points[0]})
+	}
+	return segments
+}
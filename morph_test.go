@@ -0,0 +1,72 @@
+package pathparsing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMorphPaths(t *testing.T) {
+	a := mustParsePath(t, "M0,0 L10,0 L0,10 Z")
+	b := mustParsePath(t, "M10,10 l10,0 l-10,10 z")
+	morphed, err := MorphPaths(a, b, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "M5,5 L15,5 L5,15 Z"
+	if actual := SerializeSegments(morphed); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if start, err := MorphPaths(a, b, 0); err != nil || SerializeSegments(start) != "M0,0 L10,0 L0,10 Z" {
+		t.Errorf("expected the first path at t=0, got %v, %v", start, err)
+	}
+
+	// Quadratics become cubics on both sides, so a quadratic morphs into a
+	// cubic.
+	morphed, err = MorphPaths(mustParsePath(t, "M0,0 Q15,15 30,0"), mustParsePath(t, "M0,0 C10,20 20,20 30,0"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := SerializeSegments(morphed); actual != "M0,0 C10,20 20,20 30,0" {
+		t.Errorf("unexpected morph %q", actual)
+	}
+
+	for _, other := range []string{"M0,0 L10,0 Z", "M0,0 L10,0 Q0,10 0,10 Z"} {
+		if _, err := MorphPaths(a, mustParsePath(t, other), 0.5); !errors.Is(err, ErrIncompatiblePaths) {
+			t.Errorf("%q: expected ErrIncompatiblePaths, got %v", other, err)
+		}
+	}
+}
+
+func TestMakeCompatible(t *testing.T) {
+	commands := func(segments []PathSegmentData) []SvgPathSegType {
+		var result []SvgPathSegType
+		for _, seg := range segments {
+			result = append(result, seg.Command)
+		}
+		return result
+	}
+	tests := []struct{ a, b string }{
+		{"M0,0 L10,0 L0,10 Z", "M0,0 A10,10 0 1 1 0,1 Z"},
+		{"M0,0 H10 V10 H0 Z M20,20 L30,20", "M0,0 C10,0 10,10 0,10"},
+		{"", "M0,0 L10,10"},
+	}
+	for _, test := range tests {
+		a2, b2 := MakeCompatible(mustParsePath(t, test.a), mustParsePath(t, test.b))
+		if !reflect.DeepEqual(commands(a2), commands(b2)) {
+			t.Errorf("%q and %q: command structures differ: %v and %v", test.a, test.b, commands(a2), commands(b2))
+			continue
+		}
+		if _, err := MorphPaths(a2, b2, 0.5); err != nil {
+			t.Errorf("%q and %q: %v", test.a, test.b, err)
+		}
+	}
+
+	// Resampling keeps the shape: the corners of a square split into equal
+	// edges are among the points, and the path stays closed.
+	a2, _ := MakeCompatible(mustParsePath(t, "M0,0 H10 V10 H0 Z"), mustParsePath(t, "M0,0 L1,0 L2,0 L3,0 L4,0 L5,0 L6,0 L4,4 Z"))
+	expected := "M0,0 L5,0 L10,0 L10,5 L10,10 L5,10 L0,10 L0,5 Z"
+	if actual := SerializeSegments(a2); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,203 @@
+package pathparsing
+
+import (
+	"math"
+	"strings"
+)
+
+// optimizePrecision is the number of decimals OptimizeSvgPathData keeps.
+const optimizePrecision = 3
+
+// roundCoordinate rounds v to optimizePrecision decimals, turning negative
+// zero into zero.
+func roundCoordinate(v float64) float64 {
+	scale := math.Pow(10, optimizePrecision)
+	v = math.Round(v*scale) / scale
+	if v == 0 {
+		return 0
+	}
+	return v
+}
+
+// roundOffset rounds both coordinates of p with roundCoordinate.
+func roundOffset(p PathOffset) PathOffset {
+	return PathOffset{roundCoordinate(p.Dx), roundCoordinate(p.Dy)}
+}
+
+// optimizedCommand is a candidate way of writing a segment.
+type optimizedCommand struct {
+	letter byte
+	values []float64
+}
+
+// optimizeWriter writes path data compactly, leaving out repeated command
+// letters and separators where the parser does not need them.
+type optimizeWriter struct {
+	sb         strings.Builder
+	lastLetter byte
+	lastNumber string
+}
+
+// length returns the number of characters the command adds when written.
+func (w *optimizeWriter) length(command optimizedCommand) int {
+	var probe optimizeWriter
+	probe.lastLetter = w.lastLetter
+	probe.lastNumber = w.lastNumber
+	probe.write(command)
+	return probe.sb.Len()
+}
+
+// write writes the command, omitting its letter when the parser would
+// repeat the previous command anyway.
+func (w *optimizeWriter) write(command optimizedCommand) {
+	implicit := w.lastLetter
+	switch implicit {
+	case 'M':
+		implicit = 'L'
+	case 'm':
+		implicit = 'l'
+	}
+	if command.letter != implicit || len(command.values) == 0 {
+		w.sb.WriteByte(command.letter)
+		w.lastNumber = ""
+	}
+	w.lastLetter = command.letter
+	for _, v := range command.values {
+		number := formatNumber(v)
+		if strings.HasPrefix(number, "0.") {
+			number = number[1:]
+		} else if strings.HasPrefix(number, "-0.") {
+			number = "-" + number[2:]
+		}
+		switch {
+		case w.lastNumber == "":
+		case number[0] == '-':
+		case number[0] == '.' && strings.ContainsAny(w.lastNumber, ".e"):
+		default:
+			w.sb.WriteByte(',')
+		}
+		w.sb.WriteString(number)
+		w.lastNumber = number
+	}
+}
+
+// OptimizeSvgPathData rewrites SVG path data as compactly as this package
+// can while keeping its geometry: coordinates are rounded to three decimals,
+// each segment is written with whichever of its absolute, relative and
+// shorthand (H, V, S and T) forms is shortest, repeated command letters and
+// unneeded separators are left out, and moveTos that draw nothing because
+// another moveTo or the end of the path follows are dropped.
+func OptimizeSvgPathData(svg string) (string, error) {
+	segments, err := ParsePath(svg)
+	if err != nil {
+		return "", err
+	}
+	absolute := AbsoluteSegments(segments)
+
+	var w optimizeWriter
+	var current, subpathStart, lastControl PathOffset
+	lastCommand := SvgPathSegTypeUnknown
+	for i, seg := range absolute {
+		seg.TargetPoint = roundOffset(seg.TargetPoint)
+		seg.Point1 = roundOffset(seg.Point1)
+		seg.Point2 = roundOffset(seg.Point2)
+		target := seg.TargetPoint
+		rel := PathOffset{roundCoordinate(target.Dx - current.Dx), roundCoordinate(target.Dy - current.Dy)}
+		relative := func(p PathOffset) PathOffset {
+			return PathOffset{roundCoordinate(p.Dx - current.Dx), roundCoordinate(p.Dy - current.Dy)}
+		}
+		reflected := roundOffset(current.Multiply(2).Subtract(lastControl))
+
+		var candidates []optimizedCommand
+		switch seg.Command {
+		case SvgPathSegTypeMoveToAbs:
+			if i+1 == len(absolute) || absolute[i+1].Command == SvgPathSegTypeMoveToAbs {
+				continue
+			}
+			candidates = []optimizedCommand{
+				{'M', []float64{target.Dx, target.Dy}},
+				{'m', []float64{rel.Dx, rel.Dy}},
+			}
+		case SvgPathSegTypeLineToAbs:
+			candidates = []optimizedCommand{
+				{'L', []float64{target.Dx, target.Dy}},
+				{'l', []float64{rel.Dx, rel.Dy}},
+			}
+			if target.Dy == current.Dy {
+				candidates = append(candidates, optimizedCommand{'H', []float64{target.Dx}}, optimizedCommand{'h', []float64{rel.Dx}})
+			}
+			if target.Dx == current.Dx {
+				candidates = append(candidates, optimizedCommand{'V', []float64{target.Dy}}, optimizedCommand{'v', []float64{rel.Dy}})
+			}
+		case SvgPathSegTypeCubicToAbs:
+			p1, p2 := relative(seg.Point1), relative(seg.Point2)
+			candidates = []optimizedCommand{
+				{'C', []float64{seg.Point1.Dx, seg.Point1.Dy, seg.Point2.Dx, seg.Point2.Dy, target.Dx, target.Dy}},
+				{'c', []float64{p1.Dx, p1.Dy, p2.Dx, p2.Dy, rel.Dx, rel.Dy}},
+			}
+			smooth := seg.Point1 == current
+			if lastCommand == SvgPathSegTypeCubicToAbs {
+				smooth = seg.Point1 == reflected
+			}
+			if smooth {
+				candidates = append(candidates,
+					optimizedCommand{'S', []float64{seg.Point2.Dx, seg.Point2.Dy, target.Dx, target.Dy}},
+					optimizedCommand{'s', []float64{p2.Dx, p2.Dy, rel.Dx, rel.Dy}})
+			}
+		case SvgPathSegTypeQuadToAbs:
+			p1 := relative(seg.Point1)
+			candidates = []optimizedCommand{
+				{'Q', []float64{seg.Point1.Dx, seg.Point1.Dy, target.Dx, target.Dy}},
+				{'q', []float64{p1.Dx, p1.Dy, rel.Dx, rel.Dy}},
+			}
+			smooth := seg.Point1 == current
+			if lastCommand == SvgPathSegTypeQuadToAbs {
+				smooth = seg.Point1 == reflected
+			}
+			if smooth {
+				candidates = append(candidates, optimizedCommand{'T', []float64{target.Dx, target.Dy}}, optimizedCommand{'t', []float64{rel.Dx, rel.Dy}})
+			}
+		case SvgPathSegTypeArcToAbs:
+			flags := []float64{0, 0}
+			if seg.ArcLarge {
+				flags[0] = 1
+			}
+			if seg.ArcSweep {
+				flags[1] = 1
+			}
+			radii := []float64{seg.Point1.Dx, seg.Point1.Dy, roundCoordinate(seg.ArcAngle), flags[0], flags[1]}
+			candidates = []optimizedCommand{
+				{'A', append(radii[:5:5], target.Dx, target.Dy)},
+				{'a', append(radii[:5:5], rel.Dx, rel.Dy)},
+			}
+		case SvgPathSegTypeClose:
+			candidates = []optimizedCommand{{'z', nil}}
+		}
+
+		best := candidates[0]
+		for _, candidate := range candidates[1:] {
+			if w.length(candidate) < w.length(best) {
+				best = candidate
+			}
+		}
+		w.write(best)
+
+		lastCommand = seg.Command
+		switch seg.Command {
+		case SvgPathSegTypeMoveToAbs:
+			subpathStart = target
+			current = target
+		case SvgPathSegTypeClose:
+			current = subpathStart
+		case SvgPathSegTypeCubicToAbs:
+			lastControl = seg.Point2
+			current = target
+		case SvgPathSegTypeQuadToAbs:
+			lastControl = seg.Point1
+			current = target
+		default:
+			current = target
+		}
+	}
+	return w.sb.String(), nil
+}
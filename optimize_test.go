@@ -0,0 +1,36 @@
+package pathparsing
+
+import "testing"
+
+func TestOptimizeSvgPathData(t *testing.T) {
+	verbose := "M 10.000 10.000 L 20.000 10.000 L 20.000 20.000 L 10.000 20.000 L 10.000 10.000 Z " +
+		"M 50 50 M 100.5 100.5 C 100.5 110.5 120.5 110.5 120.5 100.5 C 120.5 90.5 140.5 90.5 140.5 100.5 " +
+		"Q 150 110 160 100.00004 Q 170 90 180 100 A 5 5 0 0 1 190 100 L 190.25 99.75 M 0 0"
+	optimized, err := OptimizeSvgPathData(verbose)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "M10,10H20V20H10V10zm90.5,90.5c0,10,20,10,20,0s20-10,20,0Q150,110,160,100t20,0a5,5,0,0,1,10,0l.25-.25"
+	if optimized != expected {
+		t.Errorf("expected %q, got %q", expected, optimized)
+	}
+
+	original := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath(verbose, original); err != nil {
+		t.Fatal(err)
+	}
+	rewritten := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath(optimized, rewritten); err != nil {
+		t.Fatal(err)
+	}
+	// The lone moveTos draw nothing, so only drawing commands are compared.
+	var drawn []string
+	for _, command := range original.Commands() {
+		if command != "moveTo(50.0000, 50.0000)" && command != "moveTo(0.0000, 0.0000)" {
+			drawn = append(drawn, command)
+		}
+	}
+	if err := rewritten.Diff(drawn); err != nil {
+		t.Error(err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/go-gl/mathgl/mgl32"
 	"math"
+	"strconv"
+	"strings"
 	"unicode"
 	//"unicode"
 )
@@ -17,6 +19,15 @@ type PathProxy interface {
 	Close()
 }
 
+// quadPathProxy is implemented by the package's own path proxies that take
+// quadratic Béziers directly, which the normalizer then writes to them
+// instead of converting quadratics to cubics. Its method is unexported so
+// that other PathProxy implementations always receive cubics.
+type quadPathProxy interface {
+	PathProxy
+	quadTo(x1, y1, x2, y2 float64)
+}
+
 // PathOffset represents a 2D point with X and Y coordinates.
 type PathOffset struct {
 	Dx, Dy float64
@@ -66,38 +77,139 @@ func mapPoint(transform mgl32.Mat4, point PathOffset) PathOffset {
 
 // SvgPathParser parses SVG path data and writes it to a path.
 
+// Options controls how WriteSvgPathDataToPathWithOptions parses and
+// normalizes SVG path data. The zero value gives the behavior of
+// WriteSvgPathDataToPath.
+type Options struct {
+	// DrawCoincidentArcsAsCircle draws an arc whose endpoints coincide as a
+	// full ellipse instead of omitting it. The ellipse uses the arc's radii
+	// and rotation and is centered one x radius away from the endpoint along
+	// the rotated x axis.
+	DrawCoincidentArcsAsCircle bool
+	// ExplicitMoveToAfterClose writes a moveTo to the start of the closed
+	// subpath before a drawing command that follows a close without a moveTo
+	// of its own, so the path receives the new subpath SVG starts implicitly.
+	ExplicitMoveToAfterClose bool
+	// Delimiter is the ASCII character accepted between numbers in place of
+	// the comma, for exporters separating coordinates with semicolons for
+	// example. The zero value means a comma.
+	Delimiter rune
+	// ArcResolution, when positive, writes arcs as lineTos instead of cubics,
+	// for plotters and CNC machines that only move in straight lines. It is
+	// the largest angle in degrees, measured around the arc's center before
+	// the ellipse is scaled, that a single line may span. An arc is written
+	// as at most maxArcLines (3600) lines, so the output stays bounded for
+	// tiny resolutions.
+	ArcResolution float64
+	// OnArcRadiiCorrected, if set, is called for every arc whose radii are
+	// too small to reach from its start to its end point and so are scaled
+	// up as the SVG specification requires, letting authoring tools warn
+	// about the malformed arc. It receives the arc made absolute and the
+	// factor both radii were multiplied by, which is greater than one.
+	OnArcRadiiCorrected func(arc PathSegmentData, scale float64)
+}
+
 // WriteSvgPathDataToPath writes SVG path data to the given path.
 func WriteSvgPathDataToPath(svg string, path PathProxy) error {
-	if svg == "" {
+	return WriteSvgPathDataToPathWithOptions(svg, path, Options{})
+}
+
+// WriteSvgPathDataToPathWithOptions writes SVG path data to the given path
+// using the given options.
+func WriteSvgPathDataToPathWithOptions(svg string, path PathProxy, options Options) error {
+	if isEmptyPathData(svg) {
 		return nil
 	}
 
 	parser := newSvgPathStringSource(svg)
+	if options.Delimiter != 0 {
+		parser.delimiter = options.Delimiter
+	}
 	normalizer := NewSvgPathNormalizer()
+	normalizer.options = options
+	segments := 0
 	for parser.hasMoreData() {
 		seg, err := parser.parseSegment()
 		if err != nil {
+			if parseErr, ok := err.(*ParseError); ok {
+				parseErr.Segments = segments
+			}
 			return err
 		}
 		normalizer.emitSegment(seg, path)
+		segments++
 	}
 	return nil
 }
 
+// isEmptyPathData reports whether the path data describes an empty path,
+// either by being empty or by being the "none" keyword some tools emit.
+func isEmptyPathData(svg string) bool {
+	return svg == "" || strings.EqualFold(strings.TrimSpace(svg), "none")
+}
+
+// Errors wrapped by a ParseError to classify the problem, for use with
+// errors.Is.
+var (
+	// ErrMissingMoveTo is wrapped for path data starting with a command
+	// other than moveTo.
+	ErrMissingMoveTo = errors.New("path data must start with a moveTo command")
+	// ErrNumericOverflow is wrapped for a number or exponent outside the
+	// range of float32.
+	ErrNumericOverflow = errors.New("numeric overflow")
+	// ErrInvalidNumber is wrapped for a number that is missing or malformed.
+	ErrInvalidNumber = errors.New("invalid number")
+	// ErrMissingExponent is wrapped for an exponent marker not followed by
+	// digits.
+	ErrMissingExponent = errors.New("missing exponent")
+	// ErrInvalidFlag is wrapped for an arc flag that is missing or not 0 or
+	// 1.
+	ErrInvalidFlag = errors.New("invalid arc flag")
+)
+
+// ParseError describes malformed SVG path data.
+type ParseError struct {
+	// Offset is the byte offset in the path data where the problem was found.
+	Offset int
+	Msg    string
+	// Err classifies the problem for errors.Is, or is nil.
+	Err error
+	// Segments is the number of segments written to the path before the
+	// error, so a renderer can still show the valid portion. It is only set
+	// by the functions writing path data to a path.
+	Segments int
+}
+
+// Error returns the error message including the offset.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+}
+
+// Unwrap returns the error classifying the problem, or nil.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 // SvgPathStringSource is a source of SVG path data.
 type SvgPathStringSource struct {
 	str             string
 	previousCommand SvgPathSegType
 	idx             int
 	length          int
+	delimiter       rune
+	// recordRaw makes parseNumber append every number it parses to
+	// rawNumbers.
+	recordRaw  bool
+	rawNumbers []RawNumber
 }
 
 // newSvgPathStringSource creates a new SvgPathStringSource.
 func newSvgPathStringSource(s string) *SvgPathStringSource {
 	res := &SvgPathStringSource{
-		str:    s,
-		idx:    0,
-		length: len(s),
+		str:       s,
+		idx:       0,
+		length:    len(s),
+		delimiter: ',',
 	}
 	res.skipOptionalSvgSpaces()
 	return res
@@ -163,18 +275,22 @@ func (s *SvgPathStringSource) readCodeUnit() rune {
 // parseNumber parses a number from the string.
 func (s *SvgPathStringSource) parseNumber() (float64, error) {
 	s.skipOptionalSvgSpaces()
+	start := s.idx
 
-	sign := 1.0
 	c := s.readCodeUnit()
-	if c == '+' {
-		c = s.readCodeUnit()
-	} else if c == '-' {
-		sign = -1.0
+	hasSign := c == '+' || c == '-'
+	if hasSign {
 		c = s.readCodeUnit()
 	}
 
 	if (c < '0' || c > '9') && c != '.' {
-		return 0, errors.New("first character of a number must be one of [0-9+-.]")
+		if hasSign {
+			return 0, s.numberError(c, ErrInvalidNumber, "expected a digit or '.' after the sign")
+		}
+		if c == -1 {
+			return 0, s.numberError(c, ErrInvalidNumber, "expected a number")
+		}
+		return 0, s.numberError(c, ErrInvalidNumber, "first character of a number must be one of [0-9+-.]")
 	}
 
 	integer := 0.0
@@ -184,29 +300,24 @@ func (s *SvgPathStringSource) parseNumber() (float64, error) {
 	}
 
 	if !isValidRange(integer) {
-		return 0, errors.New("numeric overflow")
+		return 0, s.errorAt(start, ErrNumericOverflow, "numeric overflow")
 	}
 
-	decimalPart := 0.0
 	if c == '.' {
 		c = s.readCodeUnit()
 
 		if c < '0' || c > '9' {
-			return 0, errors.New("there must be at least one digit following the ")
+			return 0, s.numberError(c, ErrInvalidNumber, "there must be at least one digit following the decimal point")
 		}
 
-		frac := 1.0
 		for '0' <= c && c <= '9' {
-			frac *= 0.1
-			decimalPart += float64(c-'0') * frac
 			c = s.readCodeUnit()
 		}
 	}
 
-	number := integer + decimalPart
-	number *= sign
-
-	if s.idx < s.length && (c == 'e' || c == 'E') && (s.str[s.idx] != 'x' && s.str[s.idx] != 'm') {
+	// An 'e' followed by 'x' or 'm' is a unit like "em" rather than an
+	// exponent; a trailing 'e' is an exponent missing its digits.
+	if (c == 'e' || c == 'E') && (s.idx >= s.length || (s.str[s.idx] != 'x' && s.str[s.idx] != 'm')) {
 		c = s.readCodeUnit()
 
 		exponentIsNegative := false
@@ -218,7 +329,7 @@ func (s *SvgPathStringSource) parseNumber() (float64, error) {
 		}
 
 		if c < '0' || c > '9' {
-			return 0, errors.New("missing exponent")
+			return 0, s.numberError(c, ErrMissingExponent, "missing exponent")
 		}
 
 		exponent := 0.0
@@ -231,39 +342,61 @@ func (s *SvgPathStringSource) parseNumber() (float64, error) {
 			exponent = -exponent
 		}
 		if !isValidExponent(exponent) {
-			return 0, fmt.Errorf("invalid exponent %f", exponent)
-		}
-		if exponent != 0 {
-			number *= math.Pow(10.0, exponent)
+			return 0, s.errorAt(start, ErrNumericOverflow, fmt.Sprintf("invalid exponent %f", exponent))
 		}
 	}
 
-	if !isValidRange(number) {
-		return 0, errors.New("numeric overflow")
+	if c != -1 {
+		s.idx--
 	}
 
+	// The scanned text is valid Go float syntax; converting it as a whole
+	// rounds correctly, where summing digit by digit would not.
+	number, err := strconv.ParseFloat(s.str[start:s.idx], 64)
+	if err != nil || !isValidRange(number) {
+		return 0, s.errorAt(start, ErrNumericOverflow, "numeric overflow")
+	}
+	if s.recordRaw {
+		s.rawNumbers = append(s.rawNumbers, RawNumber{Text: s.str[start:s.idx], Value: number})
+	}
 	if c != -1 {
-		s.idx--
-		s.skipOptionalSvgSpacesOrDelimiter(',')
+		s.skipOptionalSvgSpacesOrDelimiter(s.delimiter)
 	}
 	return number, nil
 }
 
+// errorAt returns a ParseError for the given offset, classified by kind,
+// which may be nil.
+func (s *SvgPathStringSource) errorAt(offset int, kind error, msg string) error {
+	return &ParseError{Offset: offset, Msg: msg, Err: kind}
+}
+
+// numberError returns a ParseError for the character c that was just read
+// while parsing a number. Running out of data is reported as a truncated
+// number at the end of the string.
+func (s *SvgPathStringSource) numberError(c rune, kind error, msg string) error {
+	if c == -1 {
+		return &ParseError{Offset: s.length, Msg: "unexpected end of path data: " + msg, Err: kind}
+	}
+	return &ParseError{Offset: s.idx - 1, Msg: msg, Err: kind}
+}
+
 // parseArcFlag parses an arc flag from the string.
 func (s *SvgPathStringSource) parseArcFlag() (bool, error) {
 	if !s.hasMoreData() {
-		return false, errors.New("expected more data")
+		return false, s.errorAt(s.idx, ErrInvalidFlag, "unexpected end of path data, expected an arc flag")
 	}
+	flagOffset := s.idx
 	flagChar := s.str[s.idx]
 	s.idx++
-	s.skipOptionalSvgSpacesOrDelimiter(',')
+	s.skipOptionalSvgSpacesOrDelimiter(s.delimiter)
 
 	if flagChar == '0' {
 		return false, nil
 	} else if flagChar == '1' {
 		return true, nil
 	} else {
-		return false, errors.New("invalid flag value")
+		return false, s.errorAt(flagOffset, ErrInvalidFlag, "invalid flag value")
 	}
 }
 
@@ -275,7 +408,7 @@ func (s *SvgPathStringSource) hasMoreData() bool {
 // parseSegment parses a segment from the string.
 func (s *SvgPathStringSource) parseSegment() (PathSegmentData, error) {
 	if !s.hasMoreData() {
-		return PathSegmentData{}, errors.New("no more data")
+		return PathSegmentData{}, s.errorAt(s.idx, nil, "no more data")
 	}
 
 	var segment PathSegmentData
@@ -283,14 +416,21 @@ func (s *SvgPathStringSource) parseSegment() (PathSegmentData, error) {
 	command := mapLetterToSegmentType(lookahead)
 
 	if s.previousCommand == SvgPathSegTypeUnknown {
+		if command == SvgPathSegTypeUnknown {
+			return PathSegmentData{}, s.errorAt(s.idx, nil, "expected to find moveTo command")
+		}
 		if command != SvgPathSegTypeMoveToRel && command != SvgPathSegTypeMoveToAbs {
-			return PathSegmentData{}, errors.New("expected to find moveTo command")
+			return PathSegmentData{}, &ParseError{
+				Offset: s.idx,
+				Msg:    fmt.Sprintf("expected to find moveTo command, found '%c'", lookahead),
+				Err:    ErrMissingMoveTo,
+			}
 		}
 		s.idx++
 	} else if command == SvgPathSegTypeUnknown {
 		command = s.maybeImplicitCommand(lookahead, command)
 		if command == SvgPathSegTypeUnknown {
-			return PathSegmentData{}, errors.New("expected a path command")
+			return PathSegmentData{}, s.errorAt(s.idx, nil, "expected a path command")
 		}
 	} else {
 		s.idx++
@@ -400,7 +540,7 @@ func (s *SvgPathStringSource) parseSegment() (PathSegmentData, error) {
 		}
 		segment.TargetPoint = PathOffset{x, y}
 	case SvgPathSegTypeUnknown:
-		return PathSegmentData{}, errors.New("unknown segment command")
+		return PathSegmentData{}, s.errorAt(s.idx, nil, "unknown segment command")
 	}
 
 	return segment, nil
@@ -488,6 +628,39 @@ const (
 	SvgPathSegTypeClose
 )
 
+// segmentTypeNames maps segment types to the names String returns.
+var segmentTypeNames = map[SvgPathSegType]string{
+	SvgPathSegTypeUnknown:             "Unknown",
+	SvgPathSegTypeMoveToAbs:           "MoveToAbs",
+	SvgPathSegTypeMoveToRel:           "MoveToRel",
+	SvgPathSegTypeLineToAbs:           "LineToAbs",
+	SvgPathSegTypeLineToRel:           "LineToRel",
+	SvgPathSegTypeLineToHorizontalAbs: "LineToHorizontalAbs",
+	SvgPathSegTypeLineToHorizontalRel: "LineToHorizontalRel",
+	SvgPathSegTypeLineToVerticalAbs:   "LineToVerticalAbs",
+	SvgPathSegTypeLineToVerticalRel:   "LineToVerticalRel",
+	SvgPathSegTypeCubicToAbs:          "CubicToAbs",
+	SvgPathSegTypeCubicToRel:          "CubicToRel",
+	SvgPathSegTypeSmoothCubicToAbs:    "SmoothCubicToAbs",
+	SvgPathSegTypeSmoothCubicToRel:    "SmoothCubicToRel",
+	SvgPathSegTypeQuadToAbs:           "QuadToAbs",
+	SvgPathSegTypeQuadToRel:           "QuadToRel",
+	SvgPathSegTypeSmoothQuadToAbs:     "SmoothQuadToAbs",
+	SvgPathSegTypeSmoothQuadToRel:     "SmoothQuadToRel",
+	SvgPathSegTypeArcToAbs:            "ArcToAbs",
+	SvgPathSegTypeArcToRel:            "ArcToRel",
+	SvgPathSegTypeClose:               "Close",
+}
+
+// String returns the name of the segment type without its SvgPathSegType
+// prefix, such as "MoveToAbs".
+func (t SvgPathSegType) String() string {
+	if name, ok := segmentTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("SvgPathSegType(%d)", int(t))
+}
+
 // PathSegmentData represents a segment of an SVG path.
 type PathSegmentData struct {
 	Command     SvgPathSegType
@@ -510,6 +683,7 @@ type SvgPathNormalizer struct {
 	subPathPoint PathOffset
 	controlPoint PathOffset
 	lastCommand  SvgPathSegType
+	options      Options
 }
 
 // NewSvgPathNormalizer creates a new SvgPathNormalizer.
@@ -524,6 +698,117 @@ func NewSvgPathNormalizer() *SvgPathNormalizer {
 
 // emitSegment emits a normalized segment to the path.
 func (n *SvgPathNormalizer) emitSegment(segment PathSegmentData, path PathProxy) {
+	startPoint := n.currentPoint
+	afterClose := n.lastCommand == SvgPathSegTypeClose
+	normSeg := n.normalizeSegment(segment)
+
+	if n.options.ExplicitMoveToAfterClose && afterClose && normSeg.Command != SvgPathSegTypeMoveToAbs && normSeg.Command != SvgPathSegTypeClose {
+		path.MoveTo(startPoint.Dx, startPoint.Dy)
+	}
+
+	switch normSeg.Command {
+	case SvgPathSegTypeMoveToAbs:
+		path.MoveTo(normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+	case SvgPathSegTypeLineToAbs:
+		path.LineTo(normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+	case SvgPathSegTypeClose:
+		path.Close()
+	case SvgPathSegTypeCubicToAbs:
+		path.CubicTo(normSeg.Point1.Dx, normSeg.Point1.Dy, normSeg.Point2.Dx, normSeg.Point2.Dy, normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+	case SvgPathSegTypeQuadToAbs:
+		if quads, ok := path.(quadPathProxy); ok {
+			quads.quadTo(normSeg.Point1.Dx, normSeg.Point1.Dy, normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+			break
+		}
+		point1 := n.blendPoints(startPoint, normSeg.Point1)
+		point2 := n.blendPoints(normSeg.TargetPoint, normSeg.Point1)
+		path.CubicTo(point1.Dx, point1.Dy, point2.Dx, point2.Dy, normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+	case SvgPathSegTypeArcToAbs:
+		if n.options.OnArcRadiiCorrected != nil {
+			if scale := arcRadiiScale(startPoint, normSeg); scale > 1 {
+				n.options.OnArcRadiiCorrected(normSeg, scale)
+			}
+		}
+		if n.options.DrawCoincidentArcsAsCircle && normSeg.TargetPoint == startPoint && normSeg.Point1.Dx != 0 && normSeg.Point1.Dy != 0 {
+			n.emitFullEllipse(startPoint, normSeg, path)
+		} else if !n.decomposeArc(startPoint, normSeg, path) {
+			path.LineTo(normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+		}
+	}
+}
+
+// arcRadiiScale returns the factor by which the radii of an absolute arc
+// starting at point must be scaled up to reach its end point, or one if
+// they are large enough or zero.
+func arcRadiiScale(point PathOffset, arcSegment PathSegmentData) float64 {
+	rx := math.Abs(arcSegment.Point1.Dx)
+	ry := math.Abs(arcSegment.Point1.Dy)
+	if rx == 0 || ry == 0 {
+		return 1
+	}
+	sin, cos := math.Sincos(math.Pi * arcSegment.ArcAngle / 180.0)
+	mid := point.Subtract(arcSegment.TargetPoint).Multiply(0.5)
+	x := cos*mid.Dx + sin*mid.Dy
+	y := -sin*mid.Dx + cos*mid.Dy
+	if scale := x*x/(rx*rx) + y*y/(ry*ry); scale > 1 {
+		return math.Sqrt(scale)
+	}
+	return 1
+}
+
+// decomposeArc writes an arc as cubics, or as lines if the ArcResolution
+// option is set. It returns false if the arc is drawn as a line or not at
+// all, as decomposeArcToCubic does.
+func (n *SvgPathNormalizer) decomposeArc(point PathOffset, arcSegment PathSegmentData, path PathProxy) bool {
+	if n.options.ArcResolution > 0 {
+		return n.decomposeArcToLines(point, arcSegment, path)
+	}
+	return n.decomposeArcToCubic(point, arcSegment, path)
+}
+
+// maxArcLines is the most lines decomposeArcToLines writes for one arc,
+// a tenth of a degree each for a full turn.
+const maxArcLines = 3600
+
+// decomposeArcToLines writes an arc as lines each spanning at most
+// ArcResolution degrees, or as maxArcLines lines if that takes more, ending
+// exactly at the arc's target point.
+func (n *SvgPathNormalizer) decomposeArcToLines(point PathOffset, arcSegment PathSegmentData, path PathProxy) bool {
+	arc, ok := centerArc(point, arcSegment)
+	if !ok {
+		return false
+	}
+	count := maxArcLines
+	if lines := math.Ceil(math.Abs(arc.dtheta) * 180 / math.Pi / n.options.ArcResolution); lines < maxArcLines {
+		count = int(lines)
+	}
+	for i := 1; i < count; i++ {
+		p := arc.point(arc.theta1 + arc.dtheta*float64(i)/float64(count))
+		path.LineTo(p.Dx, p.Dy)
+	}
+	path.LineTo(arcSegment.TargetPoint.Dx, arcSegment.TargetPoint.Dy)
+	return true
+}
+
+// emitFullEllipse emits the full ellipse through point described by the
+// radii and rotation of an arc segment, as two half arcs.
+func (n *SvgPathNormalizer) emitFullEllipse(point PathOffset, arcSegment PathSegmentData, path PathProxy) {
+	sin, cos := math.Sincos(math.Pi * arcSegment.ArcAngle / 180.0)
+	diameter := 2 * math.Abs(arcSegment.Point1.Dx)
+	opposite := point.Translate(diameter*cos, diameter*sin)
+
+	halfArc := arcSegment
+	halfArc.TargetPoint = opposite
+	n.decomposeArc(point, halfArc, path)
+	halfArc.TargetPoint = point
+	n.decomposeArc(opposite, halfArc, path)
+}
+
+// normalizeSegment resolves a segment against the current state and returns
+// its absolute form. Relative commands are made absolute, H and V become L,
+// S becomes C and T becomes Q, so the result is always one of M, L, C, Q, A
+// or Z. The normalizer state is advanced past the segment.
+func (n *SvgPathNormalizer) normalizeSegment(segment PathSegmentData) PathSegmentData {
 	normSeg := segment
 	switch segment.Command {
 	case SvgPathSegTypeQuadToRel:
@@ -548,11 +833,10 @@ func (n *SvgPathNormalizer) emitSegment(segment PathSegmentData, path PathProxy)
 	switch segment.Command {
 	case SvgPathSegTypeMoveToRel, SvgPathSegTypeMoveToAbs:
 		n.subPathPoint = normSeg.TargetPoint
-		path.MoveTo(normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+		normSeg.Command = SvgPathSegTypeMoveToAbs
 	case SvgPathSegTypeLineToRel, SvgPathSegTypeLineToAbs, SvgPathSegTypeLineToHorizontalRel, SvgPathSegTypeLineToHorizontalAbs, SvgPathSegTypeLineToVerticalRel, SvgPathSegTypeLineToVerticalAbs:
-		path.LineTo(normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+		normSeg.Command = SvgPathSegTypeLineToAbs
 	case SvgPathSegTypeClose:
-		path.Close()
 	case SvgPathSegTypeSmoothCubicToRel, SvgPathSegTypeSmoothCubicToAbs:
 		if !n.isCubicCommand(n.lastCommand) {
 			normSeg.Point1 = n.currentPoint
@@ -562,7 +846,7 @@ func (n *SvgPathNormalizer) emitSegment(segment PathSegmentData, path PathProxy)
 		fallthrough
 	case SvgPathSegTypeCubicToRel, SvgPathSegTypeCubicToAbs:
 		n.controlPoint = normSeg.Point2
-		path.CubicTo(normSeg.Point1.Dx, normSeg.Point1.Dy, normSeg.Point2.Dx, normSeg.Point2.Dy, normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+		normSeg.Command = SvgPathSegTypeCubicToAbs
 	case SvgPathSegTypeSmoothQuadToRel, SvgPathSegTypeSmoothQuadToAbs:
 		if !n.isQuadraticCommand(n.lastCommand) {
 			normSeg.Point1 = n.currentPoint
@@ -572,13 +856,9 @@ func (n *SvgPathNormalizer) emitSegment(segment PathSegmentData, path PathProxy)
 		fallthrough
 	case SvgPathSegTypeQuadToRel, SvgPathSegTypeQuadToAbs:
 		n.controlPoint = normSeg.Point1
-		normSeg.Point1 = n.blendPoints(n.currentPoint, n.controlPoint)
-		normSeg.Point2 = n.blendPoints(normSeg.TargetPoint, n.controlPoint)
-		path.CubicTo(normSeg.Point1.Dx, normSeg.Point1.Dy, normSeg.Point2.Dx, normSeg.Point2.Dy, normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
+		normSeg.Command = SvgPathSegTypeQuadToAbs
 	case SvgPathSegTypeArcToRel, SvgPathSegTypeArcToAbs:
-		if !n.decomposeArcToCubic(n.currentPoint, normSeg, path) {
-			path.LineTo(normSeg.TargetPoint.Dx, normSeg.TargetPoint.Dy)
-		}
+		normSeg.Command = SvgPathSegTypeArcToAbs
 	default:
 		panic("invalid command type in path")
 	}
@@ -590,6 +870,7 @@ func (n *SvgPathNormalizer) emitSegment(segment PathSegmentData, path PathProxy)
 	}
 
 	n.lastCommand = segment.Command
+	return normSeg
 }
 
 // isCubicCommand checks if a command is a cubic command.
@@ -612,7 +893,13 @@ func (n *SvgPathNormalizer) blendPoints(p1, p2 PathOffset) PathOffset {
 	return PathOffset{(p1.Dx + 2*p2.Dx) / 3, (p1.Dy + 2*p2.Dy) / 3}
 }
 
-// decomposeArcToCubic decomposes an arc segment into cubic segments.
+// maxArcSegments is the most cubics an arc is decomposed into, one per
+// quarter turn.
+const maxArcSegments = 4
+
+// decomposeArcToCubic decomposes an arc segment into cubic segments. It
+// returns false without writing anything when the arc should be drawn as a
+// line instead, including when its geometry is too degenerate to compute.
 func (n *SvgPathNormalizer) decomposeArcToCubic(currentPoint PathOffset, arcSegment PathSegmentData, path PathProxy) bool {
 	rx := math.Abs(arcSegment.Point1.Dx)
 	ry := math.Abs(arcSegment.Point1.Dy)
@@ -626,27 +913,20 @@ func (n *SvgPathNormalizer) decomposeArcToCubic(currentPoint PathOffset, arcSegm
 
 	angle := math.Pi * arcSegment.ArcAngle / 180.0
 
-	midPointDistance := currentPoint.Subtract(arcSegment.TargetPoint).Multiply(0.5)
-
-	pointTransform := mgl32.HomogRotate3DZ(float32(-angle))
-
-	transformedMidPoint := mapPoint(pointTransform, PathOffset{midPointDistance.Dx, midPointDistance.Dy})
-
-	squareRx := rx * rx
-	squareRy := ry * ry
-	squareX := transformedMidPoint.Dx * transformedMidPoint.Dx
-	squareY := transformedMidPoint.Dy * transformedMidPoint.Dy
-
-	radiiScale := squareX/squareRx + squareY/squareRy
-	if radiiScale > 1.0 {
-		rx *= math.Sqrt(radiiScale)
-		ry *= math.Sqrt(radiiScale)
+	radiiScale := arcRadiiScale(currentPoint, arcSegment)
+	rx *= radiiScale
+	ry *= radiiScale
+	if !isFinite(rx) || !isFinite(ry) {
+		return false
 	}
 
-	pointTransform = mgl32.Scale3D(float32(1.0/rx), float32(1.0/ry), float32(1.0/rx)).Mul4(mgl32.HomogRotate3DZ(float32(-angle)))
+	pointTransform := mgl32.Scale3D(float32(1.0/rx), float32(1.0/ry), float32(1.0/rx)).Mul4(mgl32.HomogRotate3DZ(float32(-angle)))
 
 	point1 := mapPoint(pointTransform, currentPoint)
 	point2 := mapPoint(pointTransform, arcSegment.TargetPoint)
+	if !isFiniteOffset(point1) || !isFiniteOffset(point2) {
+		return false
+	}
 	delta := point2.Subtract(point1)
 
 	d := delta.Dx*delta.Dx + delta.Dy*delta.Dy
@@ -676,10 +956,17 @@ func (n *SvgPathNormalizer) decomposeArcToCubic(currentPoint PathOffset, arcSegm
 
 	pointTransform = mgl32.HomogRotate3DZ(float32(angle)).Mul4(mgl32.Scale3D(float32(rx), float32(ry), float32(rx)))
 
-	segments := int(math.Ceil(math.Abs(thetaArc) / (math.Pi/2 + 0.001)))
-	for i := 0; i < segments; i++ {
-		startTheta := theta1 + float64(i)*thetaArc/float64(segments)
-		endTheta := theta1 + float64(i+1)*thetaArc/float64(segments)
+	segments := math.Ceil(math.Abs(thetaArc) / (math.Pi/2 + 0.001))
+	if !isFinite(segments) || segments < 1 || segments > maxArcSegments {
+		return false
+	}
+
+	// The cubics are only written once all of them are known to be finite,
+	// so a failure never leaves a partial arc behind the line fallback.
+	var cubics [maxArcSegments]PathSegmentData
+	for i := 0; i < int(segments); i++ {
+		startTheta := theta1 + float64(i)*thetaArc/segments
+		endTheta := theta1 + float64(i+1)*thetaArc/segments
 
 		t := (8.0 / 6.0) * math.Tan(0.25*(endTheta-startTheta))
 		if !isFinite(t) {
@@ -694,18 +981,28 @@ func (n *SvgPathNormalizer) decomposeArcToCubic(currentPoint PathOffset, arcSegm
 		targetPoint := PathOffset{cosEndTheta, sinEndTheta}.Translate(centerPoint.Dx, centerPoint.Dy)
 		point2 := targetPoint.Translate(t*sinEndTheta, -t*cosEndTheta)
 
-		cubicSegment := PathSegmentData{
+		cubics[i] = PathSegmentData{
 			Command:     SvgPathSegTypeCubicToAbs,
 			Point1:      mapPoint(pointTransform, point1),
 			Point2:      mapPoint(pointTransform, point2),
 			TargetPoint: mapPoint(pointTransform, targetPoint),
 		}
+		if !isFiniteOffset(cubics[i].Point1) || !isFiniteOffset(cubics[i].Point2) || !isFiniteOffset(cubics[i].TargetPoint) {
+			return false
+		}
+	}
 
+	for _, cubicSegment := range cubics[:int(segments)] {
 		path.CubicTo(cubicSegment.Point1.Dx, cubicSegment.Point1.Dy, cubicSegment.Point2.Dx, cubicSegment.Point2.Dy, cubicSegment.TargetPoint.Dx, cubicSegment.TargetPoint.Dy)
 	}
 	return true
 }
 
+// isFiniteOffset reports whether both coordinates of the offset are finite.
+func isFiniteOffset(p PathOffset) bool {
+	return isFinite(p.Dx) && isFinite(p.Dy)
+}
+
 func isFinite(f float64) bool {
 	return !math.IsInf(f, 0) && !math.IsNaN(f) && !math.IsInf(f, 1)
 }
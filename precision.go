@@ -0,0 +1,45 @@
+package pathparsing
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ArcPrecisionTolerance is the error, in user units, above which
+// ArcPrecisionWarning flags an arc.
+const ArcPrecisionTolerance = 0.01
+
+// ArcPrecisionError estimates the error, in user units, that the float32
+// matrices used to decompose arcs into cubics introduce for an absolute arc
+// segment (see AbsoluteSegments). The arc's target point, and the point one
+// radius beyond it, are mapped into the unit circle space of the ellipse and
+// back exactly as the decomposition does, and the largest distance from
+// where they started is returned. The error grows with the magnitude of the
+// coordinates relative to the radii.
+func ArcPrecisionError(seg PathSegmentData) float64 {
+	rx := math.Abs(seg.Point1.Dx)
+	ry := math.Abs(seg.Point1.Dy)
+	if rx == 0 || ry == 0 {
+		return 0
+	}
+
+	angle := math.Pi * seg.ArcAngle / 180.0
+	toUnit := mgl32.Scale3D(float32(1.0/rx), float32(1.0/ry), float32(1.0/rx)).Mul4(mgl32.HomogRotate3DZ(float32(-angle)))
+	fromUnit := mgl32.HomogRotate3DZ(float32(angle)).Mul4(mgl32.Scale3D(float32(rx), float32(ry), float32(rx)))
+
+	maxError := 0.0
+	for _, p := range []PathOffset{seg.TargetPoint, seg.TargetPoint.Translate(rx, ry)} {
+		d := mapPoint(fromUnit, mapPoint(toUnit, p)).Subtract(p)
+		maxError = math.Max(maxError, math.Hypot(d.Dx, d.Dy))
+	}
+	return maxError
+}
+
+// ArcPrecisionWarning reports whether the float32 arc decomposition is
+// expected to place an absolute arc segment visibly off, that is further
+// than ArcPrecisionTolerance. Pre-scaling such paths down before writing
+// them and scaling the output back up avoids the error.
+func ArcPrecisionWarning(seg PathSegmentData) bool {
+	return ArcPrecisionError(seg) > ArcPrecisionTolerance
+}
@@ -0,0 +1,20 @@
+package pathparsing
+
+import "testing"
+
+func TestArcPrecisionWarning(t *testing.T) {
+	tests := []struct {
+		input   string
+		warning bool
+	}{
+		{"M0,0 A3,7 30 0 1 10,10", false},
+		{"M0,0 A300,700 30 0 1 1000,1000", false},
+		{"M1e6,1e6 A3,7 30 0 1 1000010,1000010", true},
+	}
+	for _, test := range tests {
+		segments := AbsoluteSegments(mustParsePath(t, test.input))
+		if warning := ArcPrecisionWarning(segments[1]); warning != test.warning {
+			t.Errorf("%q: expected warning %v, got %v (error %v)", test.input, test.warning, warning, ArcPrecisionError(segments[1]))
+		}
+	}
+}
@@ -0,0 +1,1195 @@
+package pathparsing
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// countingPathProxy counts the commands written to it.
+type countingPathProxy struct {
+	count int
+}
+
+func (p *countingPathProxy) MoveTo(x, y float64) {
+	p.count++
+}
+
+func (p *countingPathProxy) LineTo(x, y float64) {
+	p.count++
+}
+
+func (p *countingPathProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.count++
+}
+
+func (p *countingPathProxy) Close() {
+	p.count++
+}
+
+// CountCommands returns the number of PathProxy calls that
+// WriteSvgPathDataToPath makes for the given SVG path data.
+func CountCommands(svg string) (int, error) {
+	proxy := countingPathProxy{}
+	if err := WriteSvgPathDataToPath(svg, &proxy); err != nil {
+		return 0, err
+	}
+	return proxy.count, nil
+}
+
+// ProgressProxy forwards commands to another PathProxy and reports progress
+// after each forwarded command.
+type ProgressProxy struct {
+	path     PathProxy
+	total    int
+	done     int
+	progress func(done, total int)
+}
+
+// NewProgressProxy creates a ProgressProxy forwarding to path. The progress
+// callback is invoked after every command with the number of commands
+// forwarded so far and total, which the caller supplies (for example from
+// CountCommands). A total of zero means it is not known.
+func NewProgressProxy(path PathProxy, total int, progress func(done, total int)) *ProgressProxy {
+	return &ProgressProxy{
+		path:     path,
+		total:    total,
+		progress: progress,
+	}
+}
+
+// MoveTo forwards a move command and reports progress.
+func (p *ProgressProxy) MoveTo(x, y float64) {
+	p.path.MoveTo(x, y)
+	p.advance()
+}
+
+// LineTo forwards a line command and reports progress.
+func (p *ProgressProxy) LineTo(x, y float64) {
+	p.path.LineTo(x, y)
+	p.advance()
+}
+
+// CubicTo forwards a cubic command and reports progress.
+func (p *ProgressProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.path.CubicTo(x1, y1, x2, y2, x3, y3)
+	p.advance()
+}
+
+// Close forwards a close command and reports progress.
+func (p *ProgressProxy) Close() {
+	p.path.Close()
+	p.advance()
+}
+
+// advance counts a forwarded command and invokes the callback.
+func (p *ProgressProxy) advance() {
+	p.done++
+	if p.progress != nil {
+		p.progress(p.done, p.total)
+	}
+}
+
+// WriteSvgPathDataWithProgress writes SVG path data to the given path,
+// reporting progress as the commands are written. The path data is parsed
+// twice: once to count the commands and once to write them, so total is
+// known from the first callback on.
+func WriteSvgPathDataWithProgress(svg string, path PathProxy, progress func(done, total int)) error {
+	total, err := CountCommands(svg)
+	if err != nil {
+		return err
+	}
+	return WriteSvgPathDataToPath(svg, NewProgressProxy(path, total, progress))
+}
+
+// goSourceCall is a PathProxy call recorded by GoSourceProxy.
+type goSourceCall struct {
+	method string
+	args   []float64
+}
+
+// GoSourceProxy records PathProxy calls so they can be emitted as Go source,
+// for example to bake an icon into a binary.
+type GoSourceProxy struct {
+	calls []goSourceCall
+}
+
+// NewGoSourceProxy creates an empty GoSourceProxy.
+func NewGoSourceProxy() *GoSourceProxy {
+	return &GoSourceProxy{}
+}
+
+// MoveTo records a move command.
+func (p *GoSourceProxy) MoveTo(x, y float64) {
+	p.calls = append(p.calls, goSourceCall{"MoveTo", []float64{x, y}})
+}
+
+// LineTo records a line command.
+func (p *GoSourceProxy) LineTo(x, y float64) {
+	p.calls = append(p.calls, goSourceCall{"LineTo", []float64{x, y}})
+}
+
+// CubicTo records a cubic command.
+func (p *GoSourceProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.calls = append(p.calls, goSourceCall{"CubicTo", []float64{x1, y1, x2, y2, x3, y3}})
+}
+
+// Close records a close command.
+func (p *GoSourceProxy) Close() {
+	p.calls = append(p.calls, goSourceCall{"Close", nil})
+}
+
+// Source returns the recorded calls as Go statements invoking the methods on
+// varName, one statement per line, such as "p.MoveTo(0, 0)". NaN and
+// infinite arguments are written as calls to math.NaN and math.Inf, so
+// source containing them must import math.
+func (p *GoSourceProxy) Source(varName string) string {
+	var sb strings.Builder
+	for _, call := range p.calls {
+		sb.WriteString(varName)
+		sb.WriteByte('.')
+		sb.WriteString(call.method)
+		sb.WriteByte('(')
+		for i, arg := range call.args {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(goNumber(arg))
+		}
+		sb.WriteString(")\n")
+	}
+	return sb.String()
+}
+
+// goNumber formats v as a Go expression of its value.
+func goNumber(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "math.NaN()"
+	case math.IsInf(v, 1):
+		return "math.Inf(1)"
+	case math.IsInf(v, -1):
+		return "math.Inf(-1)"
+	}
+	return formatNumber(v)
+}
+
+// GPUBufferProxy flattens the commands written to it into a vertex buffer of
+// interleaved float32 x, y coordinates, ready for upload as line strips. Each
+// subpath is one strip; a closed subpath repeats its first vertex at the end.
+type GPUBufferProxy struct {
+	tolerance float64
+	vertices  []float32
+	offsets   []int
+	current   PathOffset
+	start     PathOffset
+	open      bool
+}
+
+// NewGPUBufferProxy creates a GPUBufferProxy flattening curves to within
+// tolerance.
+func NewGPUBufferProxy(tolerance float64) *GPUBufferProxy {
+	return &GPUBufferProxy{tolerance: tolerance}
+}
+
+// MoveTo starts a new line strip.
+func (p *GPUBufferProxy) MoveTo(x, y float64) {
+	p.start = PathOffset{x, y}
+	p.offsets = append(p.offsets, len(p.vertices)/2)
+	p.open = true
+	p.addVertex(p.start)
+}
+
+// LineTo adds a vertex to the current line strip.
+func (p *GPUBufferProxy) LineTo(x, y float64) {
+	p.ensureOpen()
+	p.addVertex(PathOffset{x, y})
+}
+
+// CubicTo flattens a cubic into vertices of the current line strip.
+func (p *GPUBufferProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.ensureOpen()
+	flattenCubic(p.current, PathOffset{x1, y1}, PathOffset{x2, y2}, PathOffset{x3, y3}, p.tolerance, p.addVertex)
+}
+
+// Close ends the current line strip at its first vertex.
+func (p *GPUBufferProxy) Close() {
+	if p.open {
+		p.addVertex(p.start)
+		p.open = false
+	}
+}
+
+// Vertices returns the interleaved x, y coordinates of all line strips.
+func (p *GPUBufferProxy) Vertices() []float32 {
+	return p.vertices
+}
+
+// SubpathOffsets returns the index of the first vertex of each line strip.
+// Strip i spans vertices SubpathOffsets()[i] up to the next offset or the
+// end of the buffer.
+func (p *GPUBufferProxy) SubpathOffsets() []int {
+	return p.offsets
+}
+
+// ensureOpen starts a new strip at the previous subpath start when drawing
+// continues after a close.
+func (p *GPUBufferProxy) ensureOpen() {
+	if !p.open {
+		p.MoveTo(p.start.Dx, p.start.Dy)
+	}
+}
+
+// addVertex appends a vertex to the buffer.
+func (p *GPUBufferProxy) addVertex(v PathOffset) {
+	p.vertices = append(p.vertices, float32(v.Dx), float32(v.Dy))
+	p.current = v
+}
+
+// RingsProxy flattens the commands written to it into coordinate lists for
+// geometry and GIS libraries. Each closed subpath becomes a ring of [x, y]
+// pairs that repeats its first coordinate at the end, as GeoJSON polygons
+// require, and each open subpath a linestring. Ring orientation is kept as
+// drawn, so the outer and inner rings of a donut keep the directions the
+// path gives them.
+type RingsProxy struct {
+	flattener flatteningPathProxy
+}
+
+// NewRingsProxy creates a RingsProxy flattening curves to within tolerance.
+func NewRingsProxy(tolerance float64) *RingsProxy {
+	return &RingsProxy{flattener: flatteningPathProxy{tolerance: tolerance}}
+}
+
+// MoveTo starts a new ring or linestring.
+func (p *RingsProxy) MoveTo(x, y float64) {
+	p.flattener.MoveTo(x, y)
+}
+
+// LineTo adds a coordinate.
+func (p *RingsProxy) LineTo(x, y float64) {
+	p.flattener.LineTo(x, y)
+}
+
+// CubicTo flattens a cubic into coordinates.
+func (p *RingsProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.flattener.CubicTo(x1, y1, x2, y2, x3, y3)
+}
+
+// Close turns the current subpath into a ring.
+func (p *RingsProxy) Close() {
+	p.flattener.Close()
+}
+
+// Rings returns the closed subpaths in the order they were drawn.
+func (p *RingsProxy) Rings() [][][]float64 {
+	return p.collect(true)
+}
+
+// LineStrings returns the open subpaths in the order they were drawn.
+func (p *RingsProxy) LineStrings() [][][]float64 {
+	return p.collect(false)
+}
+
+// collect returns the coordinates of the closed or the open subpaths.
+func (p *RingsProxy) collect(closed bool) [][][]float64 {
+	var result [][][]float64
+	for _, c := range p.flattener.contours {
+		if c.closed != closed {
+			continue
+		}
+		points := c.polyline()
+		coordinates := make([][]float64, len(points))
+		for i, point := range points {
+			coordinates[i] = []float64{point.Dx, point.Dy}
+		}
+		result = append(result, coordinates)
+	}
+	return result
+}
+
+// AsciiProxy renders the commands written to it as ASCII art for quick
+// inspection in a terminal. Curves are flattened, and the bounds of the
+// whole path are stretched to fill a grid of the given size, with '#' in
+// every cell the path passes through and spaces elsewhere. The y axis
+// points down as in SVG.
+type AsciiProxy struct {
+	width, height int
+	flattener     flatteningPathProxy
+}
+
+// NewAsciiProxy creates an AsciiProxy rendering to a grid of width columns
+// and height rows.
+func NewAsciiProxy(width, height int) *AsciiProxy {
+	return &AsciiProxy{width: width, height: height, flattener: flatteningPathProxy{tolerance: DefaultFlattenTolerance}}
+}
+
+// MoveTo starts a new subpath.
+func (p *AsciiProxy) MoveTo(x, y float64) {
+	p.flattener.MoveTo(x, y)
+}
+
+// LineTo adds a line.
+func (p *AsciiProxy) LineTo(x, y float64) {
+	p.flattener.LineTo(x, y)
+}
+
+// CubicTo flattens a cubic into lines.
+func (p *AsciiProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.flattener.CubicTo(x1, y1, x2, y2, x3, y3)
+}
+
+// Close adds the line back to the start of the subpath.
+func (p *AsciiProxy) Close() {
+	p.flattener.Close()
+}
+
+// String returns the rendered grid, one line per row without a trailing
+// newline. It is empty if the grid has no cells.
+func (p *AsciiProxy) String() string {
+	if p.width < 1 || p.height < 1 {
+		return ""
+	}
+	grid := make([][]byte, p.height)
+	for i := range grid {
+		grid[i] = bytes.Repeat([]byte{' '}, p.width)
+	}
+
+	var points []PathOffset
+	for _, c := range p.flattener.contours {
+		points = append(points, c.points...)
+	}
+	if len(points) > 0 {
+		lo, hi := points[0], points[0]
+		for _, point := range points {
+			lo = PathOffset{math.Min(lo.Dx, point.Dx), math.Min(lo.Dy, point.Dy)}
+			hi = PathOffset{math.Max(hi.Dx, point.Dx), math.Max(hi.Dy, point.Dy)}
+		}
+		scale := func(v, lo, hi float64, cells int) float64 {
+			if hi == lo {
+				return 0
+			}
+			return (v - lo) / (hi - lo) * float64(cells-1)
+		}
+		plot := func(col, row float64) {
+			grid[int(math.Round(row))][int(math.Round(col))] = '#'
+		}
+		for _, c := range p.flattener.contours {
+			line := c.polyline()
+			col0, row0 := scale(line[0].Dx, lo.Dx, hi.Dx, p.width), scale(line[0].Dy, lo.Dy, hi.Dy, p.height)
+			plot(col0, row0)
+			for _, point := range line[1:] {
+				col1, row1 := scale(point.Dx, lo.Dx, hi.Dx, p.width), scale(point.Dy, lo.Dy, hi.Dy, p.height)
+				steps := math.Ceil(math.Max(math.Abs(col1-col0), math.Abs(row1-row0)))
+				for i := 1.0; i <= steps; i++ {
+					plot(col0+(col1-col0)*i/steps, row0+(row1-row0)*i/steps)
+				}
+				col0, row0 = col1, row1
+			}
+		}
+	}
+
+	lines := make([]string, p.height)
+	for i, row := range grid {
+		lines[i] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatNumber formats a coordinate with the fewest digits that represent it
+// exactly, writing negative zero as "0". Exponents are only used within the
+// range the parser accepts, -37 to 38, so magnitudes outside it are written
+// out in full.
+func formatNumber(v float64) string {
+	v = cleanNumber(v, 0)
+	if abs := math.Abs(v); abs != 0 && (abs < 1e-37 || abs >= 1e39) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// cleanNumber turns negative zero into zero and snaps v to the nearest
+// integer if it lies within epsilon of it.
+func cleanNumber(v, epsilon float64) float64 {
+	if rounded := math.Round(v); math.Abs(v-rounded) <= epsilon {
+		v = rounded
+	}
+	if v == 0 {
+		return 0
+	}
+	return v
+}
+
+// PostScriptProxy records the commands written to it as a PostScript path
+// fragment using the moveto, lineto, curveto and closepath operators.
+type PostScriptProxy struct {
+	sb strings.Builder
+}
+
+// NewPostScriptProxy creates an empty PostScriptProxy.
+func NewPostScriptProxy() *PostScriptProxy {
+	return &PostScriptProxy{}
+}
+
+// MoveTo records a moveto operator.
+func (p *PostScriptProxy) MoveTo(x, y float64) {
+	p.writeOperator("moveto", x, y)
+}
+
+// LineTo records a lineto operator.
+func (p *PostScriptProxy) LineTo(x, y float64) {
+	p.writeOperator("lineto", x, y)
+}
+
+// CubicTo records a curveto operator, which like CubicTo takes absolute
+// control points.
+func (p *PostScriptProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.writeOperator("curveto", x1, y1, x2, y2, x3, y3)
+}
+
+// Close records a closepath operator.
+func (p *PostScriptProxy) Close() {
+	p.writeOperator("closepath")
+}
+
+// String returns the recorded path fragment, one operator per line.
+func (p *PostScriptProxy) String() string {
+	return p.sb.String()
+}
+
+// writeOperator writes the operands followed by the operator.
+func (p *PostScriptProxy) writeOperator(operator string, operands ...float64) {
+	for _, operand := range operands {
+		p.sb.WriteString(formatNumber(operand))
+		p.sb.WriteByte(' ')
+	}
+	p.sb.WriteString(operator)
+	p.sb.WriteByte('\n')
+}
+
+// DebugSvgProxy records the commands written to it as absolute SVG path data
+// for debug dumps, starting a new line for every subpath.
+type DebugSvgProxy struct {
+	buf bytes.Buffer
+}
+
+// NewDebugSvgProxy creates an empty DebugSvgProxy.
+func NewDebugSvgProxy() *DebugSvgProxy {
+	return &DebugSvgProxy{}
+}
+
+// MoveTo records an M command on a new line.
+func (p *DebugSvgProxy) MoveTo(x, y float64) {
+	if p.buf.Len() > 0 {
+		p.buf.WriteByte('\n')
+	}
+	p.writeCommand('M', x, y)
+}
+
+// LineTo records an L command.
+func (p *DebugSvgProxy) LineTo(x, y float64) {
+	p.writeCommand('L', x, y)
+}
+
+// CubicTo records a C command.
+func (p *DebugSvgProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.writeCommand('C', x1, y1, x2, y2, x3, y3)
+}
+
+// Close records a Z command.
+func (p *DebugSvgProxy) Close() {
+	p.writeCommand('Z')
+}
+
+// String returns the recorded path data, one subpath per line.
+func (p *DebugSvgProxy) String() string {
+	return p.buf.String()
+}
+
+// writeCommand writes the command letter followed by its coordinate pairs,
+// separated from the previous command on the same line by a space.
+func (p *DebugSvgProxy) writeCommand(command byte, coords ...float64) {
+	if n := p.buf.Len(); n > 0 && p.buf.Bytes()[n-1] != '\n' {
+		p.buf.WriteByte(' ')
+	}
+	p.buf.WriteByte(command)
+	for i := 0; i < len(coords); i += 2 {
+		if i > 0 {
+			p.buf.WriteByte(' ')
+		}
+		p.buf.WriteString(formatNumber(coords[i]))
+		p.buf.WriteByte(',')
+		p.buf.WriteString(formatNumber(coords[i+1]))
+	}
+}
+
+// RecordingProxy records the commands written to it as strings like
+// "moveTo(1.0000, 2.0000)", with coordinates to four decimals, for comparing
+// against expected output in tests.
+type RecordingProxy struct {
+	commands []string
+}
+
+// NewRecordingProxy creates an empty RecordingProxy.
+func NewRecordingProxy() *RecordingProxy {
+	return &RecordingProxy{}
+}
+
+// MoveTo records a moveTo command.
+func (p *RecordingProxy) MoveTo(x, y float64) {
+	p.commands = append(p.commands, fmt.Sprintf("moveTo(%.4f, %.4f)", x, y))
+}
+
+// LineTo records a lineTo command.
+func (p *RecordingProxy) LineTo(x, y float64) {
+	p.commands = append(p.commands, fmt.Sprintf("lineTo(%.4f, %.4f)", x, y))
+}
+
+// CubicTo records a cubicTo command.
+func (p *RecordingProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.commands = append(p.commands, fmt.Sprintf("cubicTo(%.4f, %.4f, %.4f, %.4f, %.4f, %.4f)", x1, y1, x2, y2, x3, y3))
+}
+
+// Close records a close command.
+func (p *RecordingProxy) Close() {
+	p.commands = append(p.commands, "close()")
+}
+
+// Commands returns the recorded commands.
+func (p *RecordingProxy) Commands() []string {
+	return p.commands
+}
+
+// Diff compares the recorded commands with the expected ones and returns an
+// error describing every difference, one per line, or nil if they are equal.
+func (p *RecordingProxy) Diff(expected []string) error {
+	var sb strings.Builder
+	for i := 0; i < len(expected) || i < len(p.commands); i++ {
+		switch {
+		case i >= len(p.commands):
+			fmt.Fprintf(&sb, "\ncommand %d: missing %s", i, expected[i])
+		case i >= len(expected):
+			fmt.Fprintf(&sb, "\ncommand %d: unexpected %s", i, p.commands[i])
+		case expected[i] != p.commands[i]:
+			fmt.Fprintf(&sb, "\ncommand %d: expected %s, got %s", i, expected[i], p.commands[i])
+		}
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	return fmt.Errorf("recorded commands differ:%s", sb.String())
+}
+
+// ErrTooManySubpaths is recorded by a MaxSubpathsProxy once the path exceeds
+// its subpath limit.
+var ErrTooManySubpaths = errors.New("too many subpaths")
+
+// MaxSubpathsProxy forwards commands to another PathProxy until more than a
+// given number of subpaths have been started, guarding against path data
+// crafted to exhaust resources. From the moveTo exceeding the limit on,
+// nothing is forwarded and Err reports ErrTooManySubpaths.
+type MaxSubpathsProxy struct {
+	path     PathProxy
+	limit    int
+	subpaths int
+	err      error
+}
+
+// NewMaxSubpathsProxy creates a MaxSubpathsProxy forwarding at most limit
+// subpaths to path.
+func NewMaxSubpathsProxy(path PathProxy, limit int) *MaxSubpathsProxy {
+	return &MaxSubpathsProxy{
+		path:  path,
+		limit: limit,
+	}
+}
+
+// MoveTo forwards a move command unless it starts a subpath over the limit.
+func (p *MaxSubpathsProxy) MoveTo(x, y float64) {
+	if p.err != nil {
+		return
+	}
+	p.subpaths++
+	if p.subpaths > p.limit {
+		p.err = ErrTooManySubpaths
+		return
+	}
+	p.path.MoveTo(x, y)
+}
+
+// LineTo forwards a line command unless the limit was exceeded.
+func (p *MaxSubpathsProxy) LineTo(x, y float64) {
+	if p.err == nil {
+		p.path.LineTo(x, y)
+	}
+}
+
+// CubicTo forwards a cubic command unless the limit was exceeded.
+func (p *MaxSubpathsProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if p.err == nil {
+		p.path.CubicTo(x1, y1, x2, y2, x3, y3)
+	}
+}
+
+// Close forwards a close command unless the limit was exceeded.
+func (p *MaxSubpathsProxy) Close() {
+	if p.err == nil {
+		p.path.Close()
+	}
+}
+
+// Err returns ErrTooManySubpaths once the limit was exceeded, or nil.
+func (p *MaxSubpathsProxy) Err() error {
+	return p.err
+}
+
+// ErrCommandBeforeMoveTo is recorded by a ValidateOrderProxy when a path is
+// drawn before its first moveTo.
+var ErrCommandBeforeMoveTo = errors.New("command before the first moveTo")
+
+// ValidateOrderProxy forwards commands to another PathProxy, checking that
+// the path starts with a moveTo, for backends that fail on drawing without a
+// current point. Commands written before the first moveTo are not forwarded
+// and Err reports the first of them wrapping ErrCommandBeforeMoveTo; the
+// rest of the path is forwarded as usual. Drawing after a close without a
+// moveTo continues from the closed subpath's start, which is valid; set
+// Options.ExplicitMoveToAfterClose for backends that need a moveTo there.
+// Paths written by this package always start with a moveTo, so this proxy
+// is a safety net for code driving proxies by hand.
+type ValidateOrderProxy struct {
+	path     PathProxy
+	moved    bool
+	commands int
+	err      error
+}
+
+// NewValidateOrderProxy creates a ValidateOrderProxy forwarding to path.
+func NewValidateOrderProxy(path PathProxy) *ValidateOrderProxy {
+	return &ValidateOrderProxy{path: path}
+}
+
+// MoveTo forwards a move command.
+func (p *ValidateOrderProxy) MoveTo(x, y float64) {
+	p.commands++
+	p.moved = true
+	p.path.MoveTo(x, y)
+}
+
+// LineTo forwards a line command if the path was started.
+func (p *ValidateOrderProxy) LineTo(x, y float64) {
+	if p.check("lineTo") {
+		p.path.LineTo(x, y)
+	}
+}
+
+// CubicTo forwards a cubic command if the path was started.
+func (p *ValidateOrderProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if p.check("cubicTo") {
+		p.path.CubicTo(x1, y1, x2, y2, x3, y3)
+	}
+}
+
+// Close forwards a close command if the path was started.
+func (p *ValidateOrderProxy) Close() {
+	if p.check("close") {
+		p.path.Close()
+	}
+}
+
+// Err returns the error for the first command written before the first
+// moveTo, or nil.
+func (p *ValidateOrderProxy) Err() error {
+	return p.err
+}
+
+// check counts a drawing command and reports whether it may be forwarded,
+// recording an error if not.
+func (p *ValidateOrderProxy) check(command string) bool {
+	p.commands++
+	if p.moved {
+		return true
+	}
+	if p.err == nil {
+		p.err = fmt.Errorf("%w: %s is command %d", ErrCommandBeforeMoveTo, command, p.commands)
+	}
+	return false
+}
+
+// ErrCoordinateBudgetExceeded is recorded by a BudgetProxy once the path
+// exceeds its coordinate budget.
+var ErrCoordinateBudgetExceeded = errors.New("coordinate budget exceeded")
+
+// BudgetProxy forwards commands to another PathProxy until the coordinate
+// values written exceed a budget, bounding the memory a single path can take
+// after arcs and curves are expanded. A moveTo or lineTo takes two values, a
+// cubic six and a close none. The command exceeding the budget and all later
+// ones are not forwarded, and Err reports ErrCoordinateBudgetExceeded.
+type BudgetProxy struct {
+	path           PathProxy
+	maxCoordinates int
+	coordinates    int
+	err            error
+}
+
+// NewBudgetProxy creates a BudgetProxy forwarding at most maxCoordinates
+// coordinate values to path.
+func NewBudgetProxy(path PathProxy, maxCoordinates int) *BudgetProxy {
+	return &BudgetProxy{
+		path:           path,
+		maxCoordinates: maxCoordinates,
+	}
+}
+
+// MoveTo forwards a move command if it fits the budget.
+func (p *BudgetProxy) MoveTo(x, y float64) {
+	if p.spend(2) {
+		p.path.MoveTo(x, y)
+	}
+}
+
+// LineTo forwards a line command if it fits the budget.
+func (p *BudgetProxy) LineTo(x, y float64) {
+	if p.spend(2) {
+		p.path.LineTo(x, y)
+	}
+}
+
+// CubicTo forwards a cubic command if it fits the budget.
+func (p *BudgetProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if p.spend(6) {
+		p.path.CubicTo(x1, y1, x2, y2, x3, y3)
+	}
+}
+
+// Close forwards a close command unless the budget was exceeded.
+func (p *BudgetProxy) Close() {
+	if p.spend(0) {
+		p.path.Close()
+	}
+}
+
+// Coordinates returns the number of coordinate values forwarded so far.
+func (p *BudgetProxy) Coordinates() int {
+	return p.coordinates
+}
+
+// Err returns ErrCoordinateBudgetExceeded once the budget was exceeded, or
+// nil.
+func (p *BudgetProxy) Err() error {
+	return p.err
+}
+
+// spend reports whether a command with n coordinate values fits the budget,
+// counting them if it does and recording the error otherwise.
+func (p *BudgetProxy) spend(n int) bool {
+	if p.err != nil {
+		return false
+	}
+	if p.coordinates+n > p.maxCoordinates {
+		p.err = ErrCoordinateBudgetExceeded
+		return false
+	}
+	p.coordinates += n
+	return true
+}
+
+// dedupeCommand is the last command forwarded by a DedupeProxy.
+type dedupeCommand struct {
+	method string
+	args   [6]float64
+}
+
+// DedupeProxy forwards commands to another PathProxy, dropping any moveTo,
+// lineTo or close identical, within an epsilon, to the command forwarded
+// immediately before it. This removes repeated moveTos and zero-length
+// repeated lineTos. A repeated cubic starts where the previous one ended
+// and so still draws, so cubics are only dropped when they have zero
+// length, with their control points and end point all at the current
+// point.
+type DedupeProxy struct {
+	path    PathProxy
+	epsilon float64
+	last    dedupeCommand
+	hasLast bool
+	current PathOffset
+	start   PathOffset
+}
+
+// NewDedupeProxy creates a DedupeProxy forwarding to path and treating
+// coordinates no more than epsilon apart as identical.
+func NewDedupeProxy(path PathProxy, epsilon float64) *DedupeProxy {
+	return &DedupeProxy{
+		path:    path,
+		epsilon: epsilon,
+	}
+}
+
+// MoveTo forwards a move command unless it repeats the previous command.
+func (p *DedupeProxy) MoveTo(x, y float64) {
+	if p.isDuplicate(dedupeCommand{"MoveTo", [6]float64{x, y}}) {
+		return
+	}
+	p.current = PathOffset{x, y}
+	p.start = p.current
+	p.path.MoveTo(x, y)
+}
+
+// LineTo forwards a line command unless it repeats the previous command.
+func (p *DedupeProxy) LineTo(x, y float64) {
+	if p.isDuplicate(dedupeCommand{"LineTo", [6]float64{x, y}}) {
+		return
+	}
+	p.current = PathOffset{x, y}
+	p.path.LineTo(x, y)
+}
+
+// CubicTo forwards a cubic command unless it has zero length.
+func (p *DedupeProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if p.isAtCurrent(x1, y1) && p.isAtCurrent(x2, y2) && p.isAtCurrent(x3, y3) {
+		return
+	}
+	p.last = dedupeCommand{"CubicTo", [6]float64{x1, y1, x2, y2, x3, y3}}
+	p.hasLast = true
+	p.current = PathOffset{x3, y3}
+	p.path.CubicTo(x1, y1, x2, y2, x3, y3)
+}
+
+// Close forwards a close command unless it repeats the previous command.
+func (p *DedupeProxy) Close() {
+	if p.isDuplicate(dedupeCommand{method: "Close"}) {
+		return
+	}
+	p.current = p.start
+	p.path.Close()
+}
+
+// isAtCurrent reports whether the point lies within epsilon of the current
+// point.
+func (p *DedupeProxy) isAtCurrent(x, y float64) bool {
+	return math.Abs(x-p.current.Dx) <= p.epsilon && math.Abs(y-p.current.Dy) <= p.epsilon
+}
+
+// isDuplicate reports whether the command matches the last forwarded one,
+// recording it as the last forwarded command otherwise.
+func (p *DedupeProxy) isDuplicate(command dedupeCommand) bool {
+	if p.hasLast && p.last.method == command.method {
+		duplicate := true
+		for i := range command.args {
+			if math.Abs(command.args[i]-p.last.args[i]) > p.epsilon {
+				duplicate = false
+				break
+			}
+		}
+		if duplicate {
+			return true
+		}
+	}
+	p.last = command
+	p.hasLast = true
+	return false
+}
+
+// MergeTinyCurvesProxy forwards commands to another PathProxy, merging every
+// cubic whose control polygon is shorter than a minimum length into an
+// adjacent cubic, such as the short curves left where arcs meet. A tiny
+// cubic following another cubic extends it to its own end point, moving the
+// second control point along to keep the end tangent; a tiny cubic starting
+// a run of cubics is absorbed by the start of the next one. A cubic absorbs
+// one tiny cubic, and further ones only while its control polygon stays
+// shorter than the minimum length, so a run of tiny cubics is merged into
+// curves of about that length rather than into one curve losing the shape.
+// A tiny cubic without an adjacent cubic is forwarded as it is. The last
+// cubic is held back until another command arrives, so call Flush after
+// writing the path.
+type MergeTinyCurvesProxy struct {
+	path      PathProxy
+	minLength float64
+	current   PathOffset
+	start     PathOffset
+	pending   [4]PathOffset
+	hasCubic  bool
+	// merged is set when the pending cubic has absorbed a tiny cubic.
+	merged bool
+}
+
+// NewMergeTinyCurvesProxy creates a MergeTinyCurvesProxy forwarding to path
+// and merging cubics shorter than minLength.
+func NewMergeTinyCurvesProxy(path PathProxy, minLength float64) *MergeTinyCurvesProxy {
+	return &MergeTinyCurvesProxy{
+		path:      path,
+		minLength: minLength,
+	}
+}
+
+// MoveTo forwards any held cubic and the move command.
+func (p *MergeTinyCurvesProxy) MoveTo(x, y float64) {
+	p.Flush()
+	p.path.MoveTo(x, y)
+	p.current = PathOffset{x, y}
+	p.start = p.current
+}
+
+// LineTo forwards any held cubic and the line command.
+func (p *MergeTinyCurvesProxy) LineTo(x, y float64) {
+	p.Flush()
+	p.path.LineTo(x, y)
+	p.current = PathOffset{x, y}
+}
+
+// CubicTo merges the cubic with the held one if either is tiny and holds
+// the result, forwarding the held cubic otherwise.
+func (p *MergeTinyCurvesProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	cubic := [4]PathOffset{p.current, {x1, y1}, {x2, y2}, {x3, y3}}
+	p.current = cubic[3]
+	extended := p.pending
+	delta := cubic[3].Subtract(extended[3])
+	extended[2] = extended[2].Add(delta)
+	extended[3] = cubic[3]
+	switch {
+	case !p.hasCubic:
+		p.pending = cubic
+		p.hasCubic = true
+	case p.isTiny(cubic) && (!p.merged || p.isTiny(extended)):
+		p.pending = extended
+		p.merged = true
+	case p.isTiny(p.pending) && !p.merged:
+		delta := p.pending[0].Subtract(cubic[0])
+		cubic[0] = p.pending[0]
+		cubic[1] = cubic[1].Add(delta)
+		p.pending = cubic
+		p.merged = true
+	default:
+		p.Flush()
+		p.pending = cubic
+		p.hasCubic = true
+	}
+}
+
+// Close forwards any held cubic and the close command.
+func (p *MergeTinyCurvesProxy) Close() {
+	p.Flush()
+	p.path.Close()
+	p.current = p.start
+}
+
+// Flush forwards the held cubic, if any.
+func (p *MergeTinyCurvesProxy) Flush() {
+	if p.hasCubic {
+		c := p.pending
+		p.path.CubicTo(c[1].Dx, c[1].Dy, c[2].Dx, c[2].Dy, c[3].Dx, c[3].Dy)
+		p.hasCubic = false
+		p.merged = false
+	}
+}
+
+// isTiny reports whether the control polygon of the cubic, which bounds its
+// length, is shorter than the minimum length.
+func (p *MergeTinyCurvesProxy) isTiny(c [4]PathOffset) bool {
+	length := 0.0
+	for i := 1; i < len(c); i++ {
+		length += math.Hypot(c[i].Dx-c[i-1].Dx, c[i].Dy-c[i-1].Dy)
+	}
+	return length < p.minLength
+}
+
+// TravelProxy measures how far a pen plotter moves the pen while drawing a
+// path and while travelling between subpaths with the pen up. Every moveTo
+// adds the distance from the current point to its target as travel, except
+// the first, since where the pen starts is up to the plotter. Lines, cubics
+// and closes add their length to the drawing distance; cubics are measured
+// flattened to DefaultFlattenTolerance.
+type TravelProxy struct {
+	current PathOffset
+	start   PathOffset
+	moved   bool
+	travel  float64
+	drawing float64
+}
+
+// NewTravelProxy creates a TravelProxy with no distance measured.
+func NewTravelProxy() *TravelProxy {
+	return &TravelProxy{}
+}
+
+// MoveTo adds the jump to the travel distance.
+func (p *TravelProxy) MoveTo(x, y float64) {
+	target := PathOffset{x, y}
+	if p.moved {
+		p.travel += math.Hypot(x-p.current.Dx, y-p.current.Dy)
+	}
+	p.moved = true
+	p.current = target
+	p.start = target
+}
+
+// LineTo adds the line's length to the drawing distance.
+func (p *TravelProxy) LineTo(x, y float64) {
+	p.drawTo(PathOffset{x, y})
+}
+
+// CubicTo adds the cubic's flattened length to the drawing distance.
+func (p *TravelProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	flattenCubic(p.current, PathOffset{x1, y1}, PathOffset{x2, y2}, PathOffset{x3, y3}, DefaultFlattenTolerance, p.drawTo)
+}
+
+// Close adds the closing line's length to the drawing distance.
+func (p *TravelProxy) Close() {
+	p.drawTo(p.start)
+}
+
+// Travel returns the distance moved with the pen up.
+func (p *TravelProxy) Travel() float64 {
+	return p.travel
+}
+
+// Drawing returns the distance moved with the pen down.
+func (p *TravelProxy) Drawing() float64 {
+	return p.drawing
+}
+
+func (p *TravelProxy) drawTo(point PathOffset) {
+	p.drawing += math.Hypot(point.Dx-p.current.Dx, point.Dy-p.current.Dy)
+	p.current = point
+}
+
+// MaxMagnitudeProxy records the largest absolute x and y coordinates
+// written to it, control points included, so a caller can tell whether a
+// path fits a constrained coordinate system such as int16 device
+// coordinates before sending it there. Since a cubic lies within its
+// control polygon, no point drawn exceeds these magnitudes.
+type MaxMagnitudeProxy struct {
+	maxX, maxY float64
+}
+
+// NewMaxMagnitudeProxy creates a MaxMagnitudeProxy with nothing recorded.
+func NewMaxMagnitudeProxy() *MaxMagnitudeProxy {
+	return &MaxMagnitudeProxy{}
+}
+
+// MoveTo records the target point.
+func (p *MaxMagnitudeProxy) MoveTo(x, y float64) {
+	p.add(x, y)
+}
+
+// LineTo records the target point.
+func (p *MaxMagnitudeProxy) LineTo(x, y float64) {
+	p.add(x, y)
+}
+
+// CubicTo records the control points and the target point.
+func (p *MaxMagnitudeProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.add(x1, y1)
+	p.add(x2, y2)
+	p.add(x3, y3)
+}
+
+// Close records nothing, since it returns to a point already recorded.
+func (p *MaxMagnitudeProxy) Close() {}
+
+// MaxX returns the largest absolute x coordinate recorded, or zero if none
+// was.
+func (p *MaxMagnitudeProxy) MaxX() float64 {
+	return p.maxX
+}
+
+// MaxY returns the largest absolute y coordinate recorded, or zero if none
+// was.
+func (p *MaxMagnitudeProxy) MaxY() float64 {
+	return p.maxY
+}
+
+// add records a point.
+func (p *MaxMagnitudeProxy) add(x, y float64) {
+	p.maxX = math.Max(p.maxX, math.Abs(x))
+	p.maxY = math.Max(p.maxY, math.Abs(y))
+}
+
+// CleanNumbersProxy forwards commands to another PathProxy with negative
+// zero coordinates turned into zero and coordinates within an epsilon of an
+// integer snapped to it, hiding the noise floating-point arithmetic leaves
+// in normalized output.
+type CleanNumbersProxy struct {
+	path    PathProxy
+	epsilon float64
+}
+
+// NewCleanNumbersProxy creates a CleanNumbersProxy forwarding to path. An
+// epsilon of zero only turns negative zeros into zeros.
+func NewCleanNumbersProxy(path PathProxy, epsilon float64) *CleanNumbersProxy {
+	return &CleanNumbersProxy{
+		path:    path,
+		epsilon: epsilon,
+	}
+}
+
+// MoveTo forwards a move command with cleaned coordinates.
+func (p *CleanNumbersProxy) MoveTo(x, y float64) {
+	p.path.MoveTo(p.clean(x), p.clean(y))
+}
+
+// LineTo forwards a line command with cleaned coordinates.
+func (p *CleanNumbersProxy) LineTo(x, y float64) {
+	p.path.LineTo(p.clean(x), p.clean(y))
+}
+
+// CubicTo forwards a cubic command with cleaned coordinates.
+func (p *CleanNumbersProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.path.CubicTo(p.clean(x1), p.clean(y1), p.clean(x2), p.clean(y2), p.clean(x3), p.clean(y3))
+}
+
+// Close forwards a close command.
+func (p *CleanNumbersProxy) Close() {
+	p.path.Close()
+}
+
+func (p *CleanNumbersProxy) clean(v float64) float64 {
+	return cleanNumber(v, p.epsilon)
+}
+
+// funcPathProxy adapts closures to the PathProxy interface. Nil closures are
+// skipped.
+type funcPathProxy struct {
+	moveTo  func(x, y float64)
+	lineTo  func(x, y float64)
+	cubicTo func(x1, y1, x2, y2, x3, y3 float64)
+	close   func()
+}
+
+func (p *funcPathProxy) MoveTo(x, y float64) {
+	if p.moveTo != nil {
+		p.moveTo(x, y)
+	}
+}
+
+func (p *funcPathProxy) LineTo(x, y float64) {
+	if p.lineTo != nil {
+		p.lineTo(x, y)
+	}
+}
+
+func (p *funcPathProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if p.cubicTo != nil {
+		p.cubicTo(x1, y1, x2, y2, x3, y3)
+	}
+}
+
+func (p *funcPathProxy) Close() {
+	if p.close != nil {
+		p.close()
+	}
+}
+
+// WriteSvgPathDataFunc writes SVG path data to the given closures instead of
+// a PathProxy, normalizing it exactly as WriteSvgPathDataToPath does. Any of
+// the closures may be nil to ignore that command.
+func WriteSvgPathDataFunc(svg string, moveTo, lineTo func(x, y float64), cubicTo func(x1, y1, x2, y2, x3, y3 float64), close func()) error {
+	return WriteSvgPathDataToPath(svg, &funcPathProxy{
+		moveTo:  moveTo,
+		lineTo:  lineTo,
+		cubicTo: cubicTo,
+		close:   close,
+	})
+}
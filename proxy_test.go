@@ -0,0 +1,447 @@
+package pathparsing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProgressProxy(t *testing.T) {
+	var calls [][2]int
+	sink := NewRecordingProxy()
+	err := WriteSvgPathDataWithProgress("M0,0 L10,0 Q15,5 10,10 Z", sink, func(done, total int) {
+		calls = append(calls, [2]int{done, total})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][2]int{{1, 4}, {2, 4}, {3, 4}, {4, 4}}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %v, got %v", i, expected[i], calls[i])
+		}
+	}
+	if len(sink.Commands()) != 4 {
+		t.Errorf("expected 4 forwarded commands, got %v", sink.Commands())
+	}
+}
+
+func TestGoSourceProxy(t *testing.T) {
+	proxy := NewGoSourceProxy()
+	if err := WriteSvgPathDataToPath("M0,0 L10.5,0 Z", proxy); err != nil {
+		t.Fatal(err)
+	}
+	source := proxy.Source("path")
+	for _, call := range []string{"path.MoveTo(0, 0)\n", "path.LineTo(10.5, 0)\n", "path.Close()\n"} {
+		if !strings.Contains(source, call) {
+			t.Errorf("expected %q in source:\n%s", call, source)
+		}
+	}
+}
+
+func TestGoSourceProxyNonFinite(t *testing.T) {
+	proxy := NewGoSourceProxy()
+	proxy.MoveTo(math.NaN(), math.Inf(1))
+	proxy.LineTo(math.Inf(-1), 1e-300)
+	source := proxy.Source("p")
+	expected := "p.MoveTo(math.NaN(), math.Inf(1))\np.LineTo(math.Inf(-1), 0." + strings.Repeat("0", 299) + "1)\n"
+	if source != expected {
+		t.Errorf("expected %q, got %q", expected, source)
+	}
+}
+
+func TestGPUBufferProxy(t *testing.T) {
+	proxy := NewGPUBufferProxy(0.1)
+	if err := WriteSvgPathDataToPath("M0,0 L10,0 L10,10 Z M20,20 L30,20", proxy); err != nil {
+		t.Fatal(err)
+	}
+	expected := []float32{0, 0, 10, 0, 10, 10, 0, 0, 20, 20, 30, 20}
+	vertices := proxy.Vertices()
+	if len(vertices) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, vertices)
+	}
+	for i := range expected {
+		if vertices[i] != expected[i] {
+			t.Errorf("coordinate %d: expected %v, got %v", i, expected[i], vertices[i])
+		}
+	}
+	if offsets := proxy.SubpathOffsets(); len(offsets) != 2 || offsets[0] != 0 || offsets[1] != 4 {
+		t.Errorf("expected offsets [0 4], got %v", offsets)
+	}
+
+	curved := NewGPUBufferProxy(0.1)
+	if err := WriteSvgPathDataToPath("M0,0 C0,10 10,10 10,0", curved); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(curved.Vertices()); n <= 4 || n%2 != 0 {
+		t.Errorf("expected the cubic to be flattened into several vertices, got %d coordinates", n)
+	}
+}
+
+func TestPostScriptProxy(t *testing.T) {
+	proxy := NewPostScriptProxy()
+	if err := WriteSvgPathDataToPath("M0,0 L10,0 C10,5 5,10 0,10.5 Z", proxy); err != nil {
+		t.Fatal(err)
+	}
+	expected := "0 0 moveto\n10 0 lineto\n10 5 5 10 0 10.5 curveto\nclosepath\n"
+	if proxy.String() != expected {
+		t.Errorf("expected %q, got %q", expected, proxy.String())
+	}
+}
+
+func TestDebugSvgProxy(t *testing.T) {
+	proxy := NewDebugSvgProxy()
+	if err := WriteSvgPathDataToPath("M0,0 h10 c0,5 -5,10 -10,10.5 z m20,20 L30,30", proxy); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(proxy.String(), "\n")
+	expected := []string{
+		"M0,0 L10,0 C10,5 5,10 0,10.5 Z",
+		"M20,20 L30,30",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %q", len(expected), proxy.String())
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestRecordingProxyDiff(t *testing.T) {
+	proxy := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath("M0,0 L10,0 L10,10", proxy); err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.Diff([]string{"moveTo(0.0000, 0.0000)", "lineTo(10.0000, 0.0000)", "lineTo(10.0000, 10.0000)"}); err != nil {
+		t.Errorf("expected no difference, got %v", err)
+	}
+
+	err := proxy.Diff([]string{"moveTo(0.0000, 0.0000)", "lineTo(5.0000, 0.0000)"})
+	if err == nil {
+		t.Fatal("expected a difference")
+	}
+	expected := "recorded commands differ:\n" +
+		"command 1: expected lineTo(5.0000, 0.0000), got lineTo(10.0000, 0.0000)\n" +
+		"command 2: unexpected lineTo(10.0000, 10.0000)"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestMaxSubpathsProxy(t *testing.T) {
+	sink := NewDeepTestPathProxy([]string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(1.0000, 0.0000)",
+		"moveTo(2.0000, 0.0000)",
+		"lineTo(3.0000, 0.0000)",
+	})
+	proxy := NewMaxSubpathsProxy(sink, 2)
+	if err := WriteSvgPathDataToPath("M0,0 L1,0 M2,0 L3,0 M4,0 L5,0 M6,0 L7,0", proxy); err != nil {
+		t.Fatal(err)
+	}
+	if proxy.Err() != ErrTooManySubpaths {
+		t.Errorf("expected ErrTooManySubpaths, got %v", proxy.Err())
+	}
+	sink.Validate()
+}
+
+func TestValidateOrderProxy(t *testing.T) {
+	sink := NewDeepTestPathProxy([]string{
+		"moveTo(1.0000, 1.0000)",
+		"lineTo(2.0000, 2.0000)",
+		"close()",
+		"lineTo(3.0000, 3.0000)",
+	})
+	proxy := NewValidateOrderProxy(sink)
+	proxy.LineTo(0, 0)
+	proxy.Close()
+	proxy.MoveTo(1, 1)
+	proxy.LineTo(2, 2)
+	proxy.Close()
+	proxy.LineTo(3, 3)
+	if !errors.Is(proxy.Err(), ErrCommandBeforeMoveTo) {
+		t.Fatalf("expected ErrCommandBeforeMoveTo, got %v", proxy.Err())
+	}
+	if expected := "command before the first moveTo: lineTo is command 1"; proxy.Err().Error() != expected {
+		t.Errorf("expected %q, got %q", expected, proxy.Err().Error())
+	}
+	sink.Validate()
+
+	proxy = NewValidateOrderProxy(NewRecordingProxy())
+	if err := WriteSvgPathDataToPath("M0,0 L1,0 Z L5,5 C1,2 3,4 5,6", proxy); err != nil {
+		t.Fatal(err)
+	}
+	if proxy.Err() != nil {
+		t.Errorf("expected parsed path data to be in order, got %v", proxy.Err())
+	}
+}
+
+func TestCleanNumbersProxy(t *testing.T) {
+	recorder := NewRecordingProxy()
+	proxy := NewCleanNumbersProxy(recorder, 1e-6)
+	proxy.MoveTo(math.Copysign(0, -1), 9.9999999)
+	proxy.CubicTo(-0.0000001, 1.5, 2.0000001, math.Copysign(0, -1), 3.25, -4)
+	proxy.Close()
+	expected := []string{
+		"moveTo(0.0000, 10.0000)",
+		"cubicTo(0.0000, 1.5000, 2.0000, 0.0000, 3.2500, -4.0000)",
+		"close()",
+	}
+	if err := recorder.Diff(expected); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRingsProxy(t *testing.T) {
+	proxy := NewRingsProxy(DefaultFlattenTolerance)
+	if err := WriteSvgPathDataToPath("M0,0 H10 V10 H0 Z M3,3 V7 H7 V3 Z M20,0 L30,0 Q35,0 35,5", proxy); err != nil {
+		t.Fatal(err)
+	}
+	expected := [][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{3, 3}, {3, 7}, {7, 7}, {7, 3}, {3, 3}},
+	}
+	if rings := proxy.Rings(); !reflect.DeepEqual(rings, expected) {
+		t.Errorf("expected rings %v, got %v", expected, rings)
+	}
+
+	lines := proxy.LineStrings()
+	if len(lines) != 1 || len(lines[0]) < 3 {
+		t.Fatalf("expected one flattened linestring, got %v", lines)
+	}
+	if first, last := lines[0][0], lines[0][len(lines[0])-1]; !reflect.DeepEqual(first, []float64{20, 0}) || !reflect.DeepEqual(last, []float64{35, 5}) {
+		t.Errorf("expected the linestring to run from (20,0) to (35,5), got %v", lines[0])
+	}
+}
+
+func TestMergeTinyCurvesProxy(t *testing.T) {
+	// An exporter split this half circle into an arc and a tiny tail arc
+	// continuing along the same circle for 0.005 radians; the tail's cubic
+	// is merged into the last cubic of the half circle, which now ends
+	// where the tail did.
+	tail := PathOffset{10 + 10*math.Cos(0.005), 10 * math.Sin(0.005)}
+	recorder := NewRecordingProxy()
+	proxy := NewMergeTinyCurvesProxy(recorder, 0.1)
+	svg := fmt.Sprintf("M0,0 A10,10 0 0 1 20,0 A10,10 0 0 1 %v,%v", tail.Dx, tail.Dy)
+	if err := WriteSvgPathDataToPath(svg, proxy); err != nil {
+		t.Fatal(err)
+	}
+	proxy.Flush()
+	err := recorder.Diff([]string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(0.0000, -5.5228, 4.4772, -10.0000, 10.0000, -10.0000)",
+		"cubicTo(15.5228, -10.0000, 19.9999, -5.4728, 19.9999, 0.0500)",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	// A run of tiny cubics is merged into curves of about the minimum
+	// length, keeping the shape of this circle of 200 cubics.
+	sink := &segmentPathProxy{}
+	proxy = NewMergeTinyCurvesProxy(sink, 1)
+	WriteSegmentsToPath(circleOfCubics(10, 200), proxy)
+	proxy.Flush()
+	cubics := 0
+	for _, seg := range sink.segments {
+		if seg.Command != SvgPathSegTypeCubicToAbs {
+			continue
+		}
+		cubics++
+		if r := math.Hypot(seg.TargetPoint.Dx, seg.TargetPoint.Dy); math.Abs(r-10) > 1e-9 {
+			t.Errorf("expected every cubic to end on the circle, got %v", seg.TargetPoint)
+		}
+	}
+	if cubics < 60 || cubics > 100 {
+		t.Errorf("expected the 200 cubics merged in groups of two or three, got %d", cubics)
+	}
+	if _, area := Measure(sink.segments, 0.001); math.Abs(area-math.Pi*100) > 1 {
+		t.Errorf("expected the circle's area kept, got %v", area)
+	}
+
+	recorder = NewRecordingProxy()
+	proxy = NewMergeTinyCurvesProxy(recorder, 0.1)
+	proxy.MoveTo(0, 0)
+	proxy.CubicTo(0, 0.01, 0.01, 0.01, 0.01, 0)
+	proxy.CubicTo(0.01, 5, 10, 5, 10, 0)
+	proxy.LineTo(20, 0)
+	proxy.CubicTo(20, 0.01, 20.01, 0.01, 20.01, 0)
+	proxy.Close()
+	err = recorder.Diff([]string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(0.0000, 5.0000, 10.0000, 5.0000, 10.0000, 0.0000)",
+		"lineTo(20.0000, 0.0000)",
+		"cubicTo(20.0000, 0.0100, 20.0100, 0.0100, 20.0100, 0.0000)",
+		"close()",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// circleOfCubics returns a closed circle of the given radius around the
+// origin made of n cubics.
+func circleOfCubics(radius float64, n int) []PathSegmentData {
+	segments := []PathSegmentData{{Command: SvgPathSegTypeMoveToAbs, TargetPoint: PathOffset{radius, 0}}}
+	step := 2 * math.Pi / float64(n)
+	k := radius * 4.0 / 3.0 * math.Tan(step/4)
+	for i := 0; i < n; i++ {
+		sin0, cos0 := math.Sincos(float64(i) * step)
+		sin1, cos1 := math.Sincos(float64(i+1) * step)
+		segments = append(segments, PathSegmentData{
+			Command:     SvgPathSegTypeCubicToAbs,
+			Point1:      PathOffset{radius*cos0 - k*sin0, radius*sin0 + k*cos0},
+			Point2:      PathOffset{radius*cos1 + k*sin1, radius*sin1 - k*cos1},
+			TargetPoint: PathOffset{radius * cos1, radius * sin1},
+		})
+	}
+	return append(segments, PathSegmentData{Command: SvgPathSegTypeClose})
+}
+
+func TestBudgetProxy(t *testing.T) {
+	sink := NewRecordingProxy()
+	proxy := NewBudgetProxy(sink, 20)
+	if err := WriteSvgPathDataToPath("M0,0 A10,10 0 1 1 0,1 Z", proxy); err != nil {
+		t.Fatal(err)
+	}
+	if proxy.Err() != ErrCoordinateBudgetExceeded {
+		t.Errorf("expected ErrCoordinateBudgetExceeded, got %v", proxy.Err())
+	}
+	if proxy.Coordinates() != 20 || len(sink.Commands()) != 4 {
+		t.Errorf("expected a moveTo and 3 cubics within the budget, got %v", sink.Commands())
+	}
+
+	proxy = NewBudgetProxy(NewRecordingProxy(), 26)
+	if err := WriteSvgPathDataToPath("M0,0 A10,10 0 1 1 0,1 Z", proxy); err != nil {
+		t.Fatal(err)
+	}
+	if proxy.Err() != nil {
+		t.Errorf("expected the path to fit, got %v", proxy.Err())
+	}
+}
+
+func TestDedupeProxy(t *testing.T) {
+	sink := NewDeepTestPathProxy([]string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(10.0000, 0.0000)",
+		"lineTo(10.0000, 10.0000)",
+		"close()",
+		"moveTo(0.0000, 0.0000)",
+	})
+	proxy := NewDedupeProxy(sink, 1e-9)
+	if err := WriteSvgPathDataToPath("M0,0 M0,0 L10,0 L10,0 L10,10 Z z M0,0", proxy); err != nil {
+		t.Fatal(err)
+	}
+	sink.Validate()
+
+	// A repeated cubic starts where the first one ended, so it still draws;
+	// only a cubic collapsed onto the current point is dropped.
+	recorder := NewRecordingProxy()
+	proxy = NewDedupeProxy(recorder, 1e-9)
+	proxy.MoveTo(0, 0)
+	proxy.CubicTo(0, 5, 10, 5, 10, 0)
+	proxy.CubicTo(0, 5, 10, 5, 10, 0)
+	proxy.CubicTo(10, 0, 10, 0, 10, 0)
+	proxy.Close()
+	err := recorder.Diff([]string{
+		"moveTo(0.0000, 0.0000)",
+		"cubicTo(0.0000, 5.0000, 10.0000, 5.0000, 10.0000, 0.0000)",
+		"cubicTo(0.0000, 5.0000, 10.0000, 5.0000, 10.0000, 0.0000)",
+		"close()",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTravelProxy(t *testing.T) {
+	proxy := NewTravelProxy()
+	if err := WriteSvgPathDataToPath("M10,10 h10 v10 z M50,40 L50,50 M0,0 C0,10 10,10 10,0", proxy); err != nil {
+		t.Fatal(err)
+	}
+	// From (10,10), back there after the close, to (50,40), then from
+	// (50,50) to the origin.
+	assertClose(t, "travel", 50+math.Hypot(50, 50), proxy.Travel(), 1e-9)
+	cubic := 0.0
+	for i, last := 1, ZeroPathOffset(); i <= 1000; i++ {
+		p := EvalCubic(PathOffset{0, 0}, PathOffset{0, 10}, PathOffset{10, 10}, PathOffset{10, 0}, float64(i)/1000)
+		cubic += math.Hypot(p.Dx-last.Dx, p.Dy-last.Dy)
+		last = p
+	}
+	assertClose(t, "drawing", 20+math.Hypot(10, 10)+10+cubic, proxy.Drawing(), 0.01)
+}
+
+func TestWriteSvgPathDataFunc(t *testing.T) {
+	var commands []string
+	err := WriteSvgPathDataFunc("M1,2 L3,4 Q5,6 7,8 Z",
+		func(x, y float64) { commands = append(commands, fmt.Sprintf("moveTo(%g, %g)", x, y)) },
+		func(x, y float64) { commands = append(commands, fmt.Sprintf("lineTo(%g, %g)", x, y)) },
+		func(x1, y1, x2, y2, x3, y3 float64) {
+			commands = append(commands, fmt.Sprintf("cubicTo(%.4g, %.4g, %.4g, %.4g, %g, %g)", x1, y1, x2, y2, x3, y3))
+		},
+		func() { commands = append(commands, "close()") },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"moveTo(1, 2)", "lineTo(3, 4)", "cubicTo(4.333, 5.333, 5.667, 6.667, 7, 8)", "close()"}
+	if strings.Join(commands, ";") != strings.Join(expected, ";") {
+		t.Errorf("expected %v, got %v", expected, commands)
+	}
+
+	lines := 0
+	err = WriteSvgPathDataFunc("M0,0 L1,1 L2,2", nil, func(x, y float64) { lines++ }, nil, nil)
+	if err != nil || lines != 2 {
+		t.Errorf("expected 2 lines with nil closures, got %d, %v", lines, err)
+	}
+}
+
+func TestAsciiProxy(t *testing.T) {
+	proxy := NewAsciiProxy(6, 4)
+	if err := WriteSvgPathDataToPath("M10,10 H110 V70 H10 Z", proxy); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"######",
+		"#    #",
+		"#    #",
+		"######",
+	}, "\n")
+	if actual := proxy.String(); actual != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, actual)
+	}
+
+	// A diagonal passes through a cell in every row.
+	proxy = NewAsciiProxy(3, 3)
+	if err := WriteSvgPathDataToPath("M0,0 L30,30", proxy); err != nil {
+		t.Fatal(err)
+	}
+	if actual := proxy.String(); actual != "#  \n # \n  #" {
+		t.Errorf("unexpected diagonal %q", actual)
+	}
+	if actual := NewAsciiProxy(0, 3).String(); actual != "" {
+		t.Errorf("expected an empty grid, got %q", actual)
+	}
+}
+
+func TestMaxMagnitudeProxy(t *testing.T) {
+	proxy := NewMaxMagnitudeProxy()
+	if err := WriteSvgPathDataToPath("M-10,5 C100,-40000 20,20 30,-8 L4,-300 Z", proxy); err != nil {
+		t.Fatal(err)
+	}
+	if proxy.MaxX() != 100 || proxy.MaxY() != 40000 {
+		t.Errorf("expected magnitudes 100 and 40000, got %v and %v", proxy.MaxX(), proxy.MaxY())
+	}
+	if empty := NewMaxMagnitudeProxy(); empty.MaxX() != 0 || empty.MaxY() != 0 {
+		t.Error("expected zero magnitudes before anything is written")
+	}
+}
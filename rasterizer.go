@@ -0,0 +1,221 @@
+package pathparsing
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+// RasterizerProxy adapts a golang.org/x/image/vector Rasterizer to the
+// PathProxy interface, so parsed paths can be filled into an image.
+// Coordinates are converted to float32 device coordinates as they are. The
+// rasterizer only fills closed subpaths, so an open subpath is closed when
+// the next one starts and by Finish for the last one.
+type RasterizerProxy struct {
+	rasterizer *vector.Rasterizer
+	open       bool
+}
+
+// NewRasterizerProxy creates a RasterizerProxy drawing into rasterizer.
+func NewRasterizerProxy(rasterizer *vector.Rasterizer) *RasterizerProxy {
+	return &RasterizerProxy{rasterizer: rasterizer}
+}
+
+// MoveTo closes any open subpath and starts a new one.
+func (p *RasterizerProxy) MoveTo(x, y float64) {
+	p.Finish()
+	p.rasterizer.MoveTo(float32(x), float32(y))
+	p.open = true
+}
+
+// LineTo adds a line to the rasterizer.
+func (p *RasterizerProxy) LineTo(x, y float64) {
+	p.rasterizer.LineTo(float32(x), float32(y))
+	p.open = true
+}
+
+// CubicTo adds a cubic Bézier to the rasterizer.
+func (p *RasterizerProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.rasterizer.CubeTo(float32(x1), float32(y1), float32(x2), float32(y2), float32(x3), float32(y3))
+	p.open = true
+}
+
+// Close closes the current subpath with a line back to its start.
+func (p *RasterizerProxy) Close() {
+	p.rasterizer.ClosePath()
+	p.open = false
+}
+
+// Finish closes the last subpath if it was left open. Call it after the path
+// has been written and before drawing the rasterizer.
+func (p *RasterizerProxy) Finish() {
+	if p.open {
+		p.Close()
+	}
+}
+
+// WriteSvgPathDataToRasterizer writes SVG path data to the rasterizer,
+// closing every subpath so the path can be filled.
+func WriteSvgPathDataToRasterizer(svg string, rasterizer *vector.Rasterizer) error {
+	proxy := NewRasterizerProxy(rasterizer)
+	if err := WriteSvgPathDataToPath(svg, proxy); err != nil {
+		return err
+	}
+	proxy.Finish()
+	return nil
+}
+
+// RasterizeAA fills the path with col into a new w×h image with
+// anti-aliased edges, leaving the rest transparent. The path is scaled
+// uniformly and centered so its bounds fit the image, and every subpath is
+// closed. The nonzero fill rule is rasterized with golang.org/x/image/vector.
+// That package has no even-odd rule, so with evenOdd set each pixel row is
+// sampled at several heights instead, filling between alternate crossings
+// and measuring their horizontal coverage exactly. The crossings are found
+// as IntersectHorizontal finds them, from y-monotonic spans the outline is
+// split into once up front. Sizes below one give an empty image.
+func RasterizeAA(segments []PathSegmentData, w, h int, col color.Color, evenOdd bool) *image.RGBA {
+	if w < 1 || h < 1 {
+		return image.NewRGBA(image.Rectangle{})
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	lo, hi := Bounds(segments)
+	fitted := segmentPathProxy{}
+	WriteSegmentsToPath(segments, NewTransformProxy(&fitted, fitAffine(lo, hi, w, h)))
+	src := image.NewUniform(col)
+	if !evenOdd {
+		rasterizer := vector.NewRasterizer(w, h)
+		proxy := NewRasterizerProxy(rasterizer)
+		WriteSegmentsToPath(fitted.segments, proxy)
+		proxy.Finish()
+		rasterizer.Draw(dst, dst.Bounds(), src, image.Point{})
+		return dst
+	}
+
+	spans := yMonotonicSpans(closeSubpaths(fitted.segments))
+	mask := image.NewAlpha(dst.Bounds())
+	var xs []float64
+	for y := 0; y < h; y++ {
+		coverage := make([]float64, w)
+		for s := 0; s < evenOddSamples; s++ {
+			xs = appendIntersections(xs[:0], spans, float64(y)+(float64(s)+0.5)/evenOddSamples)
+			for i := 0; i+1 < len(xs); i += 2 {
+				for x := math.Max(0, math.Floor(xs[i])); x < math.Min(float64(w), xs[i+1]); x++ {
+					covered := math.Min(x+1, xs[i+1]) - math.Max(x, xs[i])
+					coverage[int(x)] += covered / evenOddSamples
+				}
+			}
+		}
+		for x, c := range coverage {
+			mask.SetAlpha(x, y, color.Alpha{A: uint8(math.Round(math.Min(1, c) * 0xff))})
+		}
+	}
+	draw.DrawMask(dst, dst.Bounds(), src, image.Point{}, mask, image.Point{}, draw.Over)
+	return dst
+}
+
+// evenOddSamples is the number of heights RasterizeAA samples each pixel
+// row at for the even-odd rule.
+const evenOddSamples = 16
+
+// closeSubpaths returns segments recorded by a segmentPathProxy with a close
+// added to every subpath left open.
+func closeSubpaths(segments []PathSegmentData) []PathSegmentData {
+	result := make([]PathSegmentData, 0, len(segments)+1)
+	var start PathOffset
+	open := false
+	for _, seg := range segments {
+		switch seg.Command {
+		case SvgPathSegTypeMoveToAbs:
+			if open {
+				result = append(result, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: start})
+			}
+			start = seg.TargetPoint
+			open = false
+		case SvgPathSegTypeClose:
+			open = false
+		default:
+			open = true
+		}
+		result = append(result, seg)
+	}
+	if open {
+		result = append(result, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: start})
+	}
+	return result
+}
+
+// fitAffine returns the transform scaling the bounds with corners lo and
+// hi uniformly to fit a w×h area and centering them in it. Bounds of zero
+// extent are only centered.
+func fitAffine(lo, hi PathOffset, w, h int) Affine {
+	scale := math.Min(float64(w)/(hi.Dx-lo.Dx), float64(h)/(hi.Dy-lo.Dy))
+	if !isFinite(scale) {
+		scale = 1
+	}
+	return TranslateAffine(
+		(float64(w)-scale*(hi.Dx-lo.Dx))/2-scale*lo.Dx,
+		(float64(h)-scale*(hi.Dy-lo.Dy))/2-scale*lo.Dy,
+	).Multiply(ScaleAffine(scale, scale))
+}
+
+// perceptualHashGrid is the number of cells per side of the grid a
+// PerceptualHash is computed on, giving one bit per cell.
+const perceptualHashGrid = 8
+
+// PerceptualHash returns an average hash of the filled shape of the path for
+// detecting near-duplicate icons: geometrically similar shapes give hashes
+// differing in few bits. The path is scaled uniformly and centered to fit a
+// size×size mask, so the hash does not depend on position or scale, then
+// the mask is averaged down to an 8×8 grid and each bit is set when its cell
+// is more covered than the grid on average. Sizes below 8 are raised to 8.
+// A path enclosing nothing hashes to 0.
+func PerceptualHash(segments []PathSegmentData, size int) uint64 {
+	if size < perceptualHashGrid {
+		size = perceptualHashGrid
+	}
+	lo := PathOffset{math.Inf(1), math.Inf(1)}
+	hi := PathOffset{math.Inf(-1), math.Inf(-1)}
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		for _, p := range c.points {
+			lo = PathOffset{math.Min(lo.Dx, p.Dx), math.Min(lo.Dy, p.Dy)}
+			hi = PathOffset{math.Max(hi.Dx, p.Dx), math.Max(hi.Dy, p.Dy)}
+		}
+	}
+	extent := math.Max(hi.Dx-lo.Dx, hi.Dy-lo.Dy)
+	if !isFinite(extent) || extent == 0 {
+		return 0
+	}
+
+	transform := fitAffine(lo, hi, size, size)
+	rasterizer := vector.NewRasterizer(size, size)
+	proxy := NewRasterizerProxy(rasterizer)
+	WriteSegmentsToPath(segments, NewTransformProxy(proxy, transform))
+	proxy.Finish()
+	mask := image.NewAlpha(image.Rect(0, 0, size, size))
+	rasterizer.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	var coverage, pixels [perceptualHashGrid * perceptualHashGrid]float64
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			cell := y*perceptualHashGrid/size*perceptualHashGrid + x*perceptualHashGrid/size
+			coverage[cell] += float64(mask.AlphaAt(x, y).A)
+			pixels[cell]++
+		}
+	}
+	mean := 0.0
+	for i := range coverage {
+		coverage[i] /= pixels[i]
+		mean += coverage[i] / float64(len(coverage))
+	}
+	var hash uint64
+	for i, cell := range coverage {
+		if cell > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
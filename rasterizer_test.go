@@ -0,0 +1,109 @@
+package pathparsing
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+	"testing"
+
+	"golang.org/x/image/vector"
+)
+
+func TestWriteSvgPathDataToRasterizer(t *testing.T) {
+	rasterizer := vector.NewRasterizer(10, 10)
+	if err := WriteSvgPathDataToRasterizer("M0,0 L10,0 L0,10", rasterizer); err != nil {
+		t.Fatal(err)
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, 10, 10))
+	rasterizer.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	if a := mask.AlphaAt(1, 1).A; a != 0xff {
+		t.Errorf("expected (1, 1) to be covered, got alpha %d", a)
+	}
+	if a := mask.AlphaAt(8, 8).A; a != 0 {
+		t.Errorf("expected (8, 8) to be uncovered, got alpha %d", a)
+	}
+	if a := mask.AlphaAt(4, 5).A; a == 0 || a == 0xff {
+		t.Errorf("expected (4, 5) on the edge to be partially covered, got alpha %d", a)
+	}
+}
+
+func TestRasterizerProxyClose(t *testing.T) {
+	rasterizer := vector.NewRasterizer(20, 10)
+	proxy := NewRasterizerProxy(rasterizer)
+	if err := WriteSvgPathDataToPath("M0,0 H10 V10 H0 Z M12,0 H20 V10 H12", proxy); err != nil {
+		t.Fatal(err)
+	}
+	proxy.Finish()
+	mask := image.NewAlpha(image.Rect(0, 0, 20, 10))
+	rasterizer.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	for _, p := range []image.Point{{5, 5}, {15, 5}} {
+		if a := mask.AlphaAt(p.X, p.Y).A; a != 0xff {
+			t.Errorf("expected %v to be covered, got alpha %d", p, a)
+		}
+	}
+	if a := mask.AlphaAt(11, 5).A; a != 0 {
+		t.Errorf("expected the gap to be uncovered, got alpha %d", a)
+	}
+}
+
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func TestPerceptualHash(t *testing.T) {
+	shape := mustParsePath(t, "M10,10 L50,10 L30,45 Z M60,20 A10,10 0 1 1 60,21 Z")
+	hash := PerceptualHash(shape, 64)
+	if hash == 0 {
+		t.Fatal("expected a non-zero hash")
+	}
+	if d := hammingDistance(hash, PerceptualHash(TranslateSegments(shape, 0.7, -0.3), 64)); d > 3 {
+		t.Errorf("translated copy differs in %d bits", d)
+	}
+	if d := hammingDistance(hash, PerceptualHash(TransformSegments(shape, ScaleAffine(1.02, 1.02)), 64)); d > 3 {
+		t.Errorf("scaled copy differs in %d bits", d)
+	}
+	if d := hammingDistance(hash, PerceptualHash(mustParsePath(t, "M10,45 L50,45 L30,10 Z"), 64)); d < 8 {
+		t.Errorf("different shape differs in only %d bits", d)
+	}
+	if PerceptualHash(nil, 64) != 0 {
+		t.Error("expected an empty path to hash to 0")
+	}
+}
+
+func TestRasterizeAA(t *testing.T) {
+	circle := mustParsePath(t, "M10,0 A10,10 0 1 1 -10,0 A10,10 0 1 1 10,0 Z")
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	for _, evenOdd := range []bool{false, true} {
+		img := RasterizeAA(circle, 40, 40, red, evenOdd)
+		if c := img.RGBAAt(20, 20); c != red {
+			t.Errorf("evenOdd %v: expected the center filled, got %v", evenOdd, c)
+		}
+		if c := img.RGBAAt(1, 1); c.A != 0 {
+			t.Errorf("evenOdd %v: expected the corner empty, got %v", evenOdd, c)
+		}
+		partial := 0
+		for x := 0; x < 40; x++ {
+			if a := img.RGBAAt(x, 5).A; a != 0 && a != 0xff {
+				partial++
+			}
+		}
+		if partial == 0 {
+			t.Errorf("evenOdd %v: expected anti-aliased edge pixels", evenOdd)
+		}
+	}
+
+	// The rules differ for a square inside a square drawn the same way
+	// round, which even-odd leaves as a hole.
+	nested := mustParsePath(t, "M0,0 H30 V30 H0 Z M10,10 H20 V20 H10")
+	if a := RasterizeAA(nested, 30, 30, red, false).RGBAAt(15, 15).A; a != 0xff {
+		t.Errorf("expected nonzero to fill the inner square, got alpha %d", a)
+	}
+	evenOdd := RasterizeAA(nested, 30, 30, red, true)
+	if a := evenOdd.RGBAAt(15, 15).A; a != 0 {
+		t.Errorf("expected even-odd to leave a hole, got alpha %d", a)
+	}
+	if a := evenOdd.RGBAAt(5, 5).A; a != 0xff {
+		t.Errorf("expected even-odd to fill the ring, got alpha %d", a)
+	}
+}
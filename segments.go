@@ -0,0 +1,220 @@
+package pathparsing
+
+// ParsePath parses SVG path data into its segments without normalizing them.
+// Implicit commands are resolved, so every returned segment carries the
+// command it was parsed as, but relative and shorthand commands are kept.
+// Empty path data and the "none" keyword yield no segments.
+func ParsePath(svg string) ([]PathSegmentData, error) {
+	return ParsePathInto(svg, nil)
+}
+
+// ParsePathInto is like ParsePath but appends the segments to dst, reusing
+// its capacity, and returns the extended slice. Passing the previous result
+// resliced to zero length lets a caller parse many paths into one buffer. On
+// error dst is returned without any of the segments.
+func ParsePathInto(svg string, dst []PathSegmentData) ([]PathSegmentData, error) {
+	if isEmptyPathData(svg) {
+		return dst, nil
+	}
+
+	n := len(dst)
+	parser := newSvgPathStringSource(svg)
+	for parser.hasMoreData() {
+		seg, err := parser.parseSegment()
+		if err != nil {
+			return dst[:n], err
+		}
+		dst = append(dst, seg)
+	}
+	return dst, nil
+}
+
+// RawNumber is a number parsed from path data together with its original
+// text.
+type RawNumber struct {
+	Text  string
+	Value float64
+}
+
+// RawSegment is a parsed segment together with the numbers it was parsed
+// from, in the order they appear in the path data. Arc flags are not
+// numbers and are not recorded.
+type RawSegment struct {
+	PathSegmentData
+	Numbers []RawNumber
+}
+
+// ParsePathRaw parses SVG path data into its segments like ParsePath,
+// recording the original text of every number alongside its value, so that
+// SerializeRawSegments can write unchanged numbers exactly as they were
+// written, for tools that must not introduce precision-change diffs.
+func ParsePathRaw(svg string) ([]RawSegment, error) {
+	if isEmptyPathData(svg) {
+		return nil, nil
+	}
+
+	var segments []RawSegment
+	parser := newSvgPathStringSource(svg)
+	parser.recordRaw = true
+	for parser.hasMoreData() {
+		seg, err := parser.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, RawSegment{PathSegmentData: seg, Numbers: parser.rawNumbers})
+		parser.rawNumbers = nil
+	}
+	return segments, nil
+}
+
+// StartPoint returns the point the path starts at, which is the target of
+// its leading moveTo (a relative one being relative to the origin). Only the
+// first segment is parsed, so the rest of the path data is not checked.
+// Empty path data has no start point and yields an error.
+func StartPoint(svg string) (PathOffset, error) {
+	if isEmptyPathData(svg) {
+		return ZeroPathOffset(), &ParseError{Offset: 0, Msg: "path data is empty"}
+	}
+	seg, err := newSvgPathStringSource(svg).parseSegment()
+	if err != nil {
+		return ZeroPathOffset(), err
+	}
+	return seg.TargetPoint, nil
+}
+
+// WriteSegmentsToPath normalizes the given segments and writes them to the
+// path, exactly as WriteSvgPathDataToPath does for parsed path data.
+// Segments with an unknown command cause a panic.
+func WriteSegmentsToPath(segments []PathSegmentData, path PathProxy) {
+	normalizer := NewSvgPathNormalizer()
+	for _, seg := range segments {
+		normalizer.emitSegment(seg, path)
+	}
+}
+
+// AbsoluteSegments returns the segments with every command resolved to its
+// absolute form. Relative commands are made absolute, H and V become L, S
+// becomes C and T becomes Q with the reflected control point filled in.
+// Arcs are kept as arcs, so the result only contains M, L, C, Q, A and Z.
+func AbsoluteSegments(segments []PathSegmentData) []PathSegmentData {
+	normalizer := NewSvgPathNormalizer()
+	result := make([]PathSegmentData, 0, len(segments))
+	for _, seg := range segments {
+		result = append(result, normalizer.normalizeSegment(seg))
+	}
+	return result
+}
+
+// CleanSegments returns the segments without the horizontal and vertical
+// lines that do not move the pen, such as "H10" with the pen already at
+// x=10, which generated paths often contain. Everything else is kept as it
+// is. A no-op line followed by a smooth curve is kept too, since dropping it
+// would make the curve reflect the control point of the segment before.
+func CleanSegments(segments []PathSegmentData) []PathSegmentData {
+	normalizer := NewSvgPathNormalizer()
+	result := make([]PathSegmentData, 0, len(segments))
+	for i, seg := range segments {
+		start := normalizer.currentPoint
+		end := normalizer.normalizeSegment(seg).TargetPoint
+		if isHorizontalOrVertical(seg.Command) && end == start && !(i+1 < len(segments) && isSmoothCommand(segments[i+1].Command)) {
+			continue
+		}
+		result = append(result, seg)
+	}
+	return result
+}
+
+// isHorizontalOrVertical reports whether the command is H, h, V or v.
+func isHorizontalOrVertical(command SvgPathSegType) bool {
+	switch command {
+	case SvgPathSegTypeLineToHorizontalAbs, SvgPathSegTypeLineToHorizontalRel, SvgPathSegTypeLineToVerticalAbs, SvgPathSegTypeLineToVerticalRel:
+		return true
+	}
+	return false
+}
+
+// isSmoothCommand reports whether the command is S, s, T or t.
+func isSmoothCommand(command SvgPathSegType) bool {
+	switch command {
+	case SvgPathSegTypeSmoothCubicToAbs, SvgPathSegTypeSmoothCubicToRel, SvgPathSegTypeSmoothQuadToAbs, SvgPathSegTypeSmoothQuadToRel:
+		return true
+	}
+	return false
+}
+
+// UsedCommands parses SVG path data and tallies how often each command type
+// appears, without normalizing. Implicit commands are counted as the command
+// they repeat.
+func UsedCommands(svg string) (map[SvgPathSegType]int, error) {
+	segments, err := ParsePath(svg)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[SvgPathSegType]int)
+	for _, seg := range segments {
+		counts[seg.Command]++
+	}
+	return counts, nil
+}
+
+// ArcSegmentCount returns how many cubics WriteSvgPathDataToPath writes for
+// an absolute arc segment starting at start: one per started quarter turn,
+// or zero for an arc drawn as a line or not at all.
+func ArcSegmentCount(start PathOffset, arc PathSegmentData) int {
+	counter := countingPathProxy{}
+	NewSvgPathNormalizer().decomposeArcToCubic(start, arc, &counter)
+	return counter.count
+}
+
+// EstimateCubicCount parses SVG path data and returns how many cubics
+// WriteSvgPathDataToPath will write for it, for sizing vertex buffers before
+// rendering: one per cubic or quadratic segment plus ArcSegmentCount for
+// each arc. Nothing is written to a path. The tolerance is ignored: arcs
+// are split into a cubic per started quarter turn regardless of their size
+// or the accuracy wanted, so no tolerance changes the count.
+func EstimateCubicCount(svg string, tolerance float64) (int, error) {
+	if isEmptyPathData(svg) {
+		return 0, nil
+	}
+	count := 0
+	normalizer := NewSvgPathNormalizer()
+	parser := newSvgPathStringSource(svg)
+	for parser.hasMoreData() {
+		seg, err := parser.parseSegment()
+		if err != nil {
+			return 0, err
+		}
+		start := normalizer.currentPoint
+		switch seg = normalizer.normalizeSegment(seg); seg.Command {
+		case SvgPathSegTypeCubicToAbs, SvgPathSegTypeQuadToAbs:
+			count++
+		case SvgPathSegTypeArcToAbs:
+			count += ArcSegmentCount(start, seg)
+		}
+	}
+	return count, nil
+}
+
+// SubpathCount parses SVG path data and returns how many subpaths it has,
+// counting them as SplitSubpaths does: every moveTo starts one, and so does
+// drawing continued after a close without a moveTo. Segments are only
+// parsed, not normalized or stored.
+func SubpathCount(svg string) (int, error) {
+	if isEmptyPathData(svg) {
+		return 0, nil
+	}
+	count := 0
+	closed := false
+	parser := newSvgPathStringSource(svg)
+	for parser.hasMoreData() {
+		seg, err := parser.parseSegment()
+		if err != nil {
+			return 0, err
+		}
+		if seg.Command == SvgPathSegTypeMoveToAbs || seg.Command == SvgPathSegTypeMoveToRel || closed {
+			count++
+		}
+		closed = seg.Command == SvgPathSegTypeClose
+	}
+	return count, nil
+}
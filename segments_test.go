@@ -0,0 +1,165 @@
+package pathparsing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUsedCommands(t *testing.T) {
+	counts, err := UsedCommands("M0,0 A1,1 0 0 0 2,2 Q3,3 4,4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[SvgPathSegType]int{
+		SvgPathSegTypeMoveToAbs: 1,
+		SvgPathSegTypeArcToAbs:  1,
+		SvgPathSegTypeQuadToAbs: 1,
+	}
+	if len(counts) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, counts)
+	}
+	for command, count := range expected {
+		if counts[command] != count {
+			t.Errorf("command %v: expected %d, got %d", command, count, counts[command])
+		}
+	}
+
+	counts, err = UsedCommands("m0,0 1,1 2,2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[SvgPathSegTypeMoveToRel] != 1 || counts[SvgPathSegTypeLineToRel] != 2 {
+		t.Errorf("implicit commands miscounted: %v", counts)
+	}
+}
+
+func TestParsePathInto(t *testing.T) {
+	buf, err := ParsePathInto("M0,0 L1,1", nil)
+	if err != nil || len(buf) != 2 {
+		t.Fatalf("expected 2 segments, got %v, %v", buf, err)
+	}
+	buf, err = ParsePathInto("M2,2 L3,3 L4,4", buf[:1])
+	if err != nil || len(buf) != 4 {
+		t.Fatalf("expected 4 segments, got %v, %v", buf, err)
+	}
+	if buf[0].TargetPoint != (PathOffset{0, 0}) || buf[1].TargetPoint != (PathOffset{2, 2}) {
+		t.Errorf("segments not appended after the kept prefix: %v", buf)
+	}
+	buf, err = ParsePathInto("M0,0 L1", buf)
+	if err == nil || len(buf) != 4 {
+		t.Errorf("expected an error and the 4 previous segments, got %v, %v", buf, err)
+	}
+}
+
+func BenchmarkParsePath(b *testing.B) {
+	svg := repeatedPathData(100)
+	b.Run("ParsePath", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ParsePath(svg)
+		}
+	})
+	b.Run("ParsePathInto", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []PathSegmentData
+		for i := 0; i < b.N; i++ {
+			buf, _ = ParsePathInto(svg, buf[:0])
+		}
+	})
+}
+
+func TestStartPoint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected PathOffset
+	}{
+		{"M15,25 L...", PathOffset{15, 25}},
+		{"  m-1.5 2e1 l10,10", PathOffset{-1.5, 20}},
+	}
+	for _, test := range tests {
+		start, err := StartPoint(test.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+		} else if start != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.input, test.expected, start)
+		}
+	}
+	for _, input := range []string{"", "none", "L10,10", "M10"} {
+		if _, err := StartPoint(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestSubpathCount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"M0,0 H10 V10 Z M20,0 h5 v5 z m10,0 l1,1", 3},
+		{"M0,0 1,1 2,2", 1},
+		{"M0,0 L1,1 Z L2,2", 2},
+		{"none", 0},
+	}
+	for _, test := range tests {
+		count, err := SubpathCount(test.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+			continue
+		}
+		if count != test.expected {
+			t.Errorf("%q: expected %d, got %d", test.input, test.expected, count)
+		}
+		if segments, _ := ParsePath(test.input); len(SplitSubpaths(segments)) != count {
+			t.Errorf("%q: count differs from SplitSubpaths", test.input)
+		}
+	}
+	if _, err := SubpathCount("M0,0 L"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestEstimateCubicCount(t *testing.T) {
+	const svg = "M0,0 C1,1 2,2 3,3 s4,4 5,5 Q10,10 20,0 t5,5 A10,10 0 0 1 40,0 a10,10 0 1 1 -10,10 A0,5 0 0 1 50,50 A5,5 0 0 1 50,50 Z l5,5 a3,3 0 1 0 6,0"
+	recorder := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath(svg, recorder); err != nil {
+		t.Fatal(err)
+	}
+	cubics := 0
+	for _, command := range recorder.Commands() {
+		if strings.HasPrefix(command, "cubicTo") {
+			cubics++
+		}
+	}
+	estimate, err := EstimateCubicCount(svg, DefaultFlattenTolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate != cubics || cubics != 4+2+3+2 {
+		t.Errorf("expected the estimate to match %d recorded cubics, got %d", cubics, estimate)
+	}
+
+	if _, err := EstimateCubicCount("M0,0 A5", DefaultFlattenTolerance); err == nil {
+		t.Error("expected an error for malformed path data")
+	}
+	if count := ArcSegmentCount(PathOffset{}, PathSegmentData{Command: SvgPathSegTypeArcToAbs, Point1: PathOffset{5, 5}, TargetPoint: PathOffset{10, 0}}); count != 2 {
+		t.Errorf("expected a half circle to take 2 cubics, got %d", count)
+	}
+}
+
+func TestCleanSegments(t *testing.T) {
+	tests := []struct{ input, expected string }{
+		{"M10,10 H10 L20,20", "M10,10 L20,20"},
+		{"M10,5 V5 h0 v0 H11 v-1 V4 Z", "M10,5 H11 v-1 Z"},
+		// The pen is back at the subpath start after a close.
+		{"M1,2 L5,5 Z H1 V2 L3,3", "M1,2 L5,5 Z L3,3"},
+		// Dropping the H would make the S reflect the cubic's control point.
+		{"M0,0 C1,1 2,1 3,0 H3 S5,1 6,0", "M0,0 C1,1 2,1 3,0 H3 S5,1 6,0"},
+	}
+	for _, test := range tests {
+		actual := SerializeSegments(CleanSegments(mustParsePath(t, test.input)))
+		if actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.input, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package pathparsing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// segmentLetters maps segment types to their SVG command letters.
+var segmentLetters = map[SvgPathSegType]byte{
+	SvgPathSegTypeMoveToAbs:           'M',
+	SvgPathSegTypeMoveToRel:           'm',
+	SvgPathSegTypeLineToAbs:           'L',
+	SvgPathSegTypeLineToRel:           'l',
+	SvgPathSegTypeLineToHorizontalAbs: 'H',
+	SvgPathSegTypeLineToHorizontalRel: 'h',
+	SvgPathSegTypeLineToVerticalAbs:   'V',
+	SvgPathSegTypeLineToVerticalRel:   'v',
+	SvgPathSegTypeCubicToAbs:          'C',
+	SvgPathSegTypeCubicToRel:          'c',
+	SvgPathSegTypeSmoothCubicToAbs:    'S',
+	SvgPathSegTypeSmoothCubicToRel:    's',
+	SvgPathSegTypeQuadToAbs:           'Q',
+	SvgPathSegTypeQuadToRel:           'q',
+	SvgPathSegTypeSmoothQuadToAbs:     'T',
+	SvgPathSegTypeSmoothQuadToRel:     't',
+	SvgPathSegTypeArcToAbs:            'A',
+	SvgPathSegTypeArcToRel:            'a',
+	SvgPathSegTypeClose:               'Z',
+}
+
+// SerializeSegments returns SVG path data for the segments, writing every
+// segment with its own command letter. Nothing is normalized: relative and
+// shorthand commands are written as they are, and arcs are written as arcs
+// with their radii, rotation and flags. Numbers are written with the fewest
+// digits that parse back to the same value, so ParsePath followed by
+// SerializeSegments round-trips every value exactly, though not necessarily
+// the original text; SerializeRawSegments keeps that. Negative zero is
+// written as "0". Segments with an unknown command are skipped.
+func SerializeSegments(segments []PathSegmentData) string {
+	var sb strings.Builder
+	for _, seg := range segments {
+		writeSegment(&sb, seg, formatNumber)
+	}
+	return sb.String()
+}
+
+// writeSegment writes the segment with its command letter, separated by a
+// space from anything written before, using number to format its numbers,
+// which it calls in the order they appear in path data. Segments with an
+// unknown command are skipped.
+func writeSegment(sb *strings.Builder, seg PathSegmentData, number func(float64) string) {
+	letter, ok := segmentLetters[seg.Command]
+	if !ok {
+		return
+	}
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	sb.WriteByte(letter)
+	switch seg.Command {
+	case SvgPathSegTypeMoveToAbs, SvgPathSegTypeMoveToRel, SvgPathSegTypeLineToAbs, SvgPathSegTypeLineToRel, SvgPathSegTypeSmoothQuadToAbs, SvgPathSegTypeSmoothQuadToRel:
+		writePoint(sb, seg.TargetPoint, number)
+	case SvgPathSegTypeLineToHorizontalAbs, SvgPathSegTypeLineToHorizontalRel:
+		sb.WriteString(number(seg.TargetPoint.Dx))
+	case SvgPathSegTypeLineToVerticalAbs, SvgPathSegTypeLineToVerticalRel:
+		sb.WriteString(number(seg.TargetPoint.Dy))
+	case SvgPathSegTypeCubicToAbs, SvgPathSegTypeCubicToRel:
+		writePoint(sb, seg.Point1, number)
+		sb.WriteByte(' ')
+		fallthrough
+	case SvgPathSegTypeSmoothCubicToAbs, SvgPathSegTypeSmoothCubicToRel:
+		writePoint(sb, seg.Point2, number)
+		sb.WriteByte(' ')
+		writePoint(sb, seg.TargetPoint, number)
+	case SvgPathSegTypeQuadToAbs, SvgPathSegTypeQuadToRel:
+		writePoint(sb, seg.Point1, number)
+		sb.WriteByte(' ')
+		writePoint(sb, seg.TargetPoint, number)
+	case SvgPathSegTypeArcToAbs, SvgPathSegTypeArcToRel:
+		writePoint(sb, seg.Point1, number)
+		sb.WriteByte(' ')
+		sb.WriteString(number(seg.ArcAngle))
+		sb.WriteByte(' ')
+		writeFlag(sb, seg.ArcLarge)
+		sb.WriteByte(' ')
+		writeFlag(sb, seg.ArcSweep)
+		sb.WriteByte(' ')
+		writePoint(sb, seg.TargetPoint, number)
+	}
+}
+
+// SerializeRawSegments returns SVG path data for segments parsed by
+// ParsePathRaw like SerializeSegments, but writes every number whose value
+// is unchanged with its original text, so an optimizer rewriting some
+// segments leaves the others exactly as they were, down to "0.10" or
+// "1e2". Numbers that were changed, and those of segments with fewer
+// recorded numbers than their command takes, are formatted as by
+// SerializeSegments. Separators are written as by SerializeSegments too.
+func SerializeRawSegments(segments []RawSegment) string {
+	var sb strings.Builder
+	for _, seg := range segments {
+		next := 0
+		writeSegment(&sb, seg.PathSegmentData, func(v float64) string {
+			i := next
+			next++
+			if i < len(seg.Numbers) && seg.Numbers[i].Value == v {
+				return seg.Numbers[i].Text
+			}
+			return formatNumber(v)
+		})
+	}
+	return sb.String()
+}
+
+// SerializeOptions controls how SerializeSegmentsWithOptions writes path
+// data. The zero value gives the output of SerializeSegments.
+type SerializeOptions struct {
+	// IntegerEpsilon snaps every number within this distance of an integer
+	// to that integer, so values like 9.999999999 computed by the
+	// normalizer are written as 10.
+	IntegerEpsilon float64
+}
+
+// SerializeSegmentsWithOptions returns SVG path data for the segments like
+// SerializeSegments, using the given options.
+func SerializeSegmentsWithOptions(segments []PathSegmentData, options SerializeOptions) string {
+	if options.IntegerEpsilon <= 0 {
+		return SerializeSegments(segments)
+	}
+	clean := func(p PathOffset) PathOffset {
+		return PathOffset{cleanNumber(p.Dx, options.IntegerEpsilon), cleanNumber(p.Dy, options.IntegerEpsilon)}
+	}
+	cleaned := make([]PathSegmentData, len(segments))
+	for i, seg := range segments {
+		seg.TargetPoint = clean(seg.TargetPoint)
+		seg.Point1 = clean(seg.Point1)
+		seg.Point2 = clean(seg.Point2)
+		seg.ArcAngle = cleanNumber(seg.ArcAngle, options.IntegerEpsilon)
+		cleaned[i] = seg
+	}
+	return SerializeSegments(cleaned)
+}
+
+// writePoint writes a coordinate pair formatted by number and separated by
+// a comma.
+func writePoint(sb *strings.Builder, p PathOffset, number func(float64) string) {
+	sb.WriteString(number(p.Dx))
+	sb.WriteByte(',')
+	sb.WriteString(number(p.Dy))
+}
+
+// writeFlag writes an arc flag.
+func writeFlag(sb *strings.Builder, flag bool) {
+	if flag {
+		sb.WriteByte('1')
+	} else {
+		sb.WriteByte('0')
+	}
+}
+
+// FormatSegments returns a readable dump of the segments for debugging, one
+// segment per line with its index, command name and the coordinates it
+// uses, such as "1: CubicToAbs p1=(1,2) p2=(3,4) to=(5,6)".
+func FormatSegments(segments []PathSegmentData) string {
+	lines := make([]string, len(segments))
+	for i, seg := range segments {
+		lines[i] = fmt.Sprintf("%d: %s", i, formatSegment(seg))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatSegmentsCompact returns the dump of FormatSegments on one line,
+// without indices and with segments separated by "; ".
+func FormatSegmentsCompact(segments []PathSegmentData) string {
+	parts := make([]string, len(segments))
+	for i, seg := range segments {
+		parts[i] = formatSegment(seg)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatSegment returns the command name of the segment followed by the
+// coordinates its command uses.
+func formatSegment(seg PathSegmentData) string {
+	point := func(p PathOffset) string {
+		return "(" + formatNumber(p.Dx) + "," + formatNumber(p.Dy) + ")"
+	}
+	var fields []string
+	switch seg.Command {
+	case SvgPathSegTypeLineToHorizontalAbs, SvgPathSegTypeLineToHorizontalRel:
+		fields = []string{"x=" + formatNumber(seg.TargetPoint.Dx)}
+	case SvgPathSegTypeLineToVerticalAbs, SvgPathSegTypeLineToVerticalRel:
+		fields = []string{"y=" + formatNumber(seg.TargetPoint.Dy)}
+	case SvgPathSegTypeCubicToAbs, SvgPathSegTypeCubicToRel:
+		fields = []string{"p1=" + point(seg.Point1), "p2=" + point(seg.Point2), "to=" + point(seg.TargetPoint)}
+	case SvgPathSegTypeSmoothCubicToAbs, SvgPathSegTypeSmoothCubicToRel:
+		fields = []string{"p2=" + point(seg.Point2), "to=" + point(seg.TargetPoint)}
+	case SvgPathSegTypeQuadToAbs, SvgPathSegTypeQuadToRel:
+		fields = []string{"p1=" + point(seg.Point1), "to=" + point(seg.TargetPoint)}
+	case SvgPathSegTypeArcToAbs, SvgPathSegTypeArcToRel:
+		fields = []string{
+			"r=" + point(seg.Point1),
+			"angle=" + formatNumber(seg.ArcAngle),
+			fmt.Sprintf("large=%t", seg.ArcLarge),
+			fmt.Sprintf("sweep=%t", seg.ArcSweep),
+			"to=" + point(seg.TargetPoint),
+		}
+	case SvgPathSegTypeClose:
+	default:
+		fields = []string{"to=" + point(seg.TargetPoint)}
+	}
+	return strings.TrimSpace(seg.Command.String() + " " + strings.Join(fields, " "))
+}
@@ -0,0 +1,131 @@
+package pathparsing
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSerializeSegmentsRoundTrip(t *testing.T) {
+	tests := []string{
+		"M0,0 A5,3 30 1 0 10,0",
+		"m1.5,-2 a5,3 -45 0 1 10,0 Z",
+		"M0,0 L1,2 H3 V4 C5,6 7,8 9,10 S11,12 13,14 Q15,16 17,18 T19,20 Z",
+		"m0,0 l1,2 h3 v4 c5,6 7,8 9,10 s11,12 13,14 q15,16 17,18 t19,20 Z",
+		"M0.3,0.7 L1.1,2.2 l-0.1,33.333 A0.6,0.9 12.7 0 1 0.01,1e-07",
+		"M123456.789,-98765.4321 C0.1,0.2 0.3,0.4 0.5,0.6",
+	}
+	for _, input := range tests {
+		segments := mustParsePath(t, input)
+		serialized := SerializeSegments(segments)
+		if serialized != input {
+			t.Errorf("expected %q, got %q", input, serialized)
+		}
+		assertSegmentsEqual(t, segments, mustParsePath(t, serialized))
+	}
+}
+
+func TestSerializeSegmentsKeepsArcs(t *testing.T) {
+	segments := mustParsePath(t, "M0,0 A5,3 30 1 0 10,0")
+	transformed := TransformSegments(segments, TranslateAffine(1, 1))
+	if serialized := SerializeSegments(transformed); serialized != "M1,1 A5,3 30 1 0 11,1" {
+		t.Errorf("unexpected serialization %q", serialized)
+	}
+}
+
+func TestSerializeSegmentsNegativeZero(t *testing.T) {
+	mirrored := mustParsePath(t, "M0,0 L10,0")
+	for i := range mirrored {
+		mirrored[i].TargetPoint = mirrored[i].TargetPoint.Multiply(-1)
+	}
+	if !math.Signbit(mirrored[0].TargetPoint.Dx) {
+		t.Fatalf("expected the mirrored origin to be negative zero, got %v", mirrored[0].TargetPoint)
+	}
+	if serialized := SerializeSegments(mirrored); serialized != "M0,0 L-10,0" {
+		t.Errorf("expected %q, got %q", "M0,0 L-10,0", serialized)
+	}
+
+	rotated := TransformSegments(mustParsePath(t, "M10,0 L0,10"), RotateAffine(math.Pi/2))
+	expected := "M0,10 L-10,0"
+	if serialized := SerializeSegmentsWithOptions(rotated, SerializeOptions{IntegerEpsilon: 1e-9}); serialized != expected {
+		t.Errorf("expected %q, got %q", expected, serialized)
+	}
+	if serialized := SerializeSegments(rotated); serialized == expected {
+		t.Errorf("expected rotation to leave rounding noise without snapping, got %q", serialized)
+	}
+}
+
+func TestSerializeSegmentsExtremeMagnitudes(t *testing.T) {
+	segments := []PathSegmentData{
+		{Command: SvgPathSegTypeMoveToAbs, TargetPoint: PathOffset{1e-300, -1e39}},
+		{Command: SvgPathSegTypeLineToAbs, TargetPoint: PathOffset{5e38, 1e-37}},
+	}
+	serialized := SerializeSegments(segments)
+	if !strings.Contains(serialized, "L5e+38,1e-37") {
+		t.Errorf("expected exponents the parser accepts to be kept, got %q", serialized)
+	}
+	parsed, err := ParsePath(serialized)
+	if err != nil {
+		t.Fatalf("%q: %v", serialized, err)
+	}
+	for i := range segments {
+		for _, pair := range [][2]float64{
+			{segments[i].TargetPoint.Dx, parsed[i].TargetPoint.Dx},
+			{segments[i].TargetPoint.Dy, parsed[i].TargetPoint.Dy},
+		} {
+			if math.Abs(pair[1]-pair[0]) > math.Abs(pair[0])*1e-9 {
+				t.Errorf("segment %d: expected %v, got %v", i, pair[0], pair[1])
+			}
+		}
+	}
+}
+
+func TestFormatSegments(t *testing.T) {
+	segments := mustParsePath(t, "M10,20 h5 C1,2 3,4 5,6 A5,5 0 0 1 20,20 Z")
+	formatted := FormatSegments(segments)
+	for _, name := range []string{"MoveToAbs", "LineToHorizontalRel", "CubicToAbs", "ArcToAbs", "Close"} {
+		if !strings.Contains(formatted, name) {
+			t.Errorf("expected %q in %q", name, formatted)
+		}
+	}
+	lines := strings.Split(formatted, "\n")
+	if len(lines) != len(segments) {
+		t.Fatalf("expected %d lines, got %q", len(segments), formatted)
+	}
+	if expected := "2: CubicToAbs p1=(1,2) p2=(3,4) to=(5,6)"; lines[2] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[2])
+	}
+
+	expected := "MoveToAbs to=(10,20); LineToHorizontalRel x=5; Close"
+	if actual := FormatSegmentsCompact(mustParsePath(t, "M10,20 h5 Z")); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if name := SvgPathSegType(99).String(); name != "SvgPathSegType(99)" {
+		t.Errorf("unexpected name %q", name)
+	}
+}
+
+func TestSerializeRawSegments(t *testing.T) {
+	segments, err := ParsePathRaw("M0.10 0.20 L+1e2,.50 A5.0 5 0 1 0 10,0 z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numbers := segments[0].Numbers; len(numbers) != 2 || numbers[0].Text != "0.10" || numbers[1].Text != "0.20" {
+		t.Errorf("expected the original number strings, got %v", numbers)
+	}
+	expected := "M0.10,0.20 L+1e2,.50 A5.0,5 0 1 0 10,0 Z"
+	if actual := SerializeRawSegments(segments); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	// A changed number is formatted afresh while the rest keep their text.
+	segments[1].TargetPoint.Dy = 0.75
+	expected = "M0.10,0.20 L+1e2,0.75 A5.0,5 0 1 0 10,0 Z"
+	if actual := SerializeRawSegments(segments); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	if _, err := ParsePathRaw("M0,0 L1"); err == nil {
+		t.Error("expected an error for malformed path data")
+	}
+}
@@ -0,0 +1,119 @@
+package pathparsing
+
+// EllipseToSegments returns the path of an SVG ellipse element as four
+// absolute arcs running clockwise from its rightmost point, followed by a
+// close. An ellipse with a non-positive radius is not rendered, so nil is
+// returned.
+func EllipseToSegments(cx, cy, rx, ry float64) []PathSegmentData {
+	if rx <= 0 || ry <= 0 {
+		return nil
+	}
+	radii := PathOffset{rx, ry}
+	arc := func(x, y float64) PathSegmentData {
+		return PathSegmentData{Command: SvgPathSegTypeArcToAbs, TargetPoint: PathOffset{x, y}, Point1: radii, ArcSweep: true}
+	}
+	return []PathSegmentData{
+		{Command: SvgPathSegTypeMoveToAbs, TargetPoint: PathOffset{cx + rx, cy}},
+		arc(cx, cy+ry),
+		arc(cx-rx, cy),
+		arc(cx, cy-ry),
+		arc(cx+rx, cy),
+		{Command: SvgPathSegTypeClose, TargetPoint: PathOffset{cx + rx, cy}},
+	}
+}
+
+// CircleToSegments returns the path of an SVG circle element, as described
+// for EllipseToSegments.
+func CircleToSegments(cx, cy, r float64) []PathSegmentData {
+	return EllipseToSegments(cx, cy, r, r)
+}
+
+// circleKappa is the distance of the control points from the ends of a cubic
+// approximating a quarter of the unit circle.
+const circleKappa = 0.5522847498
+
+// EllipseToCubicSegments returns the path of an SVG ellipse element as four
+// absolute cubics, one per quarter, running clockwise from its rightmost
+// point and followed by a close, for consumers that do not handle arcs. An
+// ellipse with a non-positive radius is not rendered, so nil is returned.
+func EllipseToCubicSegments(cx, cy, rx, ry float64) []PathSegmentData {
+	if rx <= 0 || ry <= 0 {
+		return nil
+	}
+	kx, ky := circleKappa*rx, circleKappa*ry
+	cubic := func(x1, y1, x2, y2, x, y float64) PathSegmentData {
+		return PathSegmentData{
+			Command:     SvgPathSegTypeCubicToAbs,
+			Point1:      PathOffset{x1, y1},
+			Point2:      PathOffset{x2, y2},
+			TargetPoint: PathOffset{x, y},
+		}
+	}
+	return []PathSegmentData{
+		{Command: SvgPathSegTypeMoveToAbs, TargetPoint: PathOffset{cx + rx, cy}},
+		cubic(cx+rx, cy+ky, cx+kx, cy+ry, cx, cy+ry),
+		cubic(cx-kx, cy+ry, cx-rx, cy+ky, cx-rx, cy),
+		cubic(cx-rx, cy-ky, cx-kx, cy-ry, cx, cy-ry),
+		cubic(cx+kx, cy-ry, cx+rx, cy-ky, cx+rx, cy),
+		{Command: SvgPathSegTypeClose, TargetPoint: PathOffset{cx + rx, cy}},
+	}
+}
+
+// CircleToCubicSegments returns the path of an SVG circle element, as
+// described for EllipseToCubicSegments.
+func CircleToCubicSegments(cx, cy, r float64) []PathSegmentData {
+	return EllipseToCubicSegments(cx, cy, r, r)
+}
+
+// RectToSegments returns the path of an SVG rect element as absolute lines
+// running clockwise from the top left, with an arc for each rounded corner,
+// followed by a close. As for the auto value in SVG, a non-positive corner
+// radius takes the value of the other one, and the radii are clamped to half
+// the width and height; lines of zero length between corners are left out.
+// A rect with a non-positive size is not rendered, so nil is returned.
+func RectToSegments(x, y, w, h, rx, ry float64) []PathSegmentData {
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	if rx <= 0 {
+		rx = ry
+	}
+	if ry <= 0 {
+		ry = rx
+	}
+	if rx <= 0 {
+		rx, ry = 0, 0
+	}
+	if rx > w/2 {
+		rx = w / 2
+	}
+	if ry > h/2 {
+		ry = h / 2
+	}
+
+	line := func(x, y float64) PathSegmentData {
+		return PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: PathOffset{x, y}}
+	}
+	segments := []PathSegmentData{{Command: SvgPathSegTypeMoveToAbs, TargetPoint: PathOffset{x + rx, y}}}
+	if rx == 0 {
+		segments = append(segments, line(x+w, y), line(x+w, y+h), line(x, y+h))
+	} else {
+		radii := PathOffset{rx, ry}
+		arc := func(x, y float64) PathSegmentData {
+			return PathSegmentData{Command: SvgPathSegTypeArcToAbs, TargetPoint: PathOffset{x, y}, Point1: radii, ArcSweep: true}
+		}
+		for _, seg := range []PathSegmentData{
+			line(x+w-rx, y), arc(x+w, y+ry),
+			line(x+w, y+h-ry), arc(x+w-rx, y+h),
+			line(x+rx, y+h), arc(x, y+h-ry),
+			line(x, y+ry), arc(x+rx, y),
+		} {
+			// Radii clamped to half the size leave nothing between corners.
+			if seg.Command == SvgPathSegTypeLineToAbs && seg.TargetPoint == segments[len(segments)-1].TargetPoint {
+				continue
+			}
+			segments = append(segments, seg)
+		}
+	}
+	return append(segments, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: PathOffset{x + rx, y}})
+}
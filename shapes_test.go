@@ -0,0 +1,77 @@
+package pathparsing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCircleToSegments(t *testing.T) {
+	circle := CircleToSegments(10, 10, 5)
+	if len(circle) == 0 || circle[len(circle)-1].Command != SvgPathSegTypeClose {
+		t.Fatalf("expected a closed path, got %v", circle)
+	}
+	assertClose(t, "area", 25*math.Pi, SignedArea(circle), 0.5)
+	expected := "M15,10 A5,5 0 0 1 10,15 A5,5 0 0 1 5,10 A5,5 0 0 1 10,5 A5,5 0 0 1 15,10 Z"
+	if actual := SerializeSegments(circle); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if CircleToSegments(0, 0, 0) != nil {
+		t.Error("expected no segments for a zero radius")
+	}
+}
+
+func TestCircleToCubicSegments(t *testing.T) {
+	const r = 100.0
+	circle := CircleToCubicSegments(10, 20, r)
+	for i := 1; i <= 4; i++ {
+		if circle[i].Command != SvgPathSegTypeCubicToAbs {
+			t.Fatalf("segment %d: expected a cubic, got %v", i, circle[i].Command)
+		}
+		p0 := circle[i-1].TargetPoint
+		for k := 0; k <= 16; k++ {
+			p := EvalCubic(p0, circle[i].Point1, circle[i].Point2, circle[i].TargetPoint, float64(k)/16)
+			radius := math.Hypot(p.Dx-10, p.Dy-20)
+			if math.Abs(radius-r) > 0.0003*r {
+				t.Errorf("segment %d at t=%v: expected radius %v, got %v", i, float64(k)/16, r, radius)
+			}
+		}
+	}
+	if circle[len(circle)-1].Command != SvgPathSegTypeClose {
+		t.Error("expected a closed path")
+	}
+}
+
+func TestEllipseToSegments(t *testing.T) {
+	assertClose(t, "area", 6*math.Pi, SignedArea(EllipseToSegments(0, 0, 3, 2)), 0.5)
+}
+
+func TestRectToSegments(t *testing.T) {
+	expected := "M0,0 L10,0 L10,5 L0,5 Z"
+	if actual := SerializeSegments(RectToSegments(0, 0, 10, 5, 0, 0)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	rounded := RectToSegments(0, 0, 10, 6, 2, 0)
+	arcs := 0
+	for _, seg := range rounded {
+		if seg.Command == SvgPathSegTypeArcToAbs {
+			arcs++
+			if seg.Point1 != (PathOffset{2, 2}) {
+				t.Errorf("expected corner radii (2, 2), got %v", seg.Point1)
+			}
+		}
+	}
+	if arcs != 4 {
+		t.Errorf("expected 4 arc corners, got %d", arcs)
+	}
+	assertClose(t, "rounded area", 60-(4-math.Pi)*4, SignedArea(rounded), 0.1)
+
+	expected = "M5,0 A5,3 0 0 1 10,3 A5,3 0 0 1 5,6 A5,3 0 0 1 0,3 A5,3 0 0 1 5,0 Z"
+	if actual := SerializeSegments(RectToSegments(0, 0, 10, 6, 20, 20)); actual != expected {
+		t.Errorf("expected clamped radii without lines, %q, got %q", expected, actual)
+	}
+	expected = "M3,0 L7,0 A3,3 0 0 1 10,3 A3,3 0 0 1 7,6 L3,6 A3,3 0 0 1 0,3 A3,3 0 0 1 3,0 Z"
+	if actual := SerializeSegments(RectToSegments(0, 0, 10, 6, 3, 0)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,39 @@
+package pathparsing
+
+// MergeCollinear returns the path with every lineTo vertex removed where the
+// line arriving at it and the line leaving it point in directions within
+// angleTol radians of each other, so runs of collinear lines become a single
+// line. The merged line is compared against the next one, so a slow curve
+// may drift by up to angleTol at each removed vertex. Zero-length lines
+// followed by another line are removed as well. The result is absolute (see
+// AbsoluteSegments).
+func MergeCollinear(segments []PathSegmentData, angleTol float64) []PathSegmentData {
+	var result []PathSegmentData
+	var current, subpathStart, lastStart PathOffset
+	for _, seg := range AbsoluteSegments(segments) {
+		if seg.Command == SvgPathSegTypeLineToAbs && len(result) > 0 && result[len(result)-1].Command == SvgPathSegTypeLineToAbs {
+			last := &result[len(result)-1]
+			incoming := last.TargetPoint.Subtract(lastStart)
+			outgoing := seg.TargetPoint.Subtract(last.TargetPoint)
+			if incoming == ZeroPathOffset() || outgoing == ZeroPathOffset() ||
+				angleBetween(incoming.Direction(), outgoing.Direction()) <= angleTol {
+				last.TargetPoint = seg.TargetPoint
+				current = seg.TargetPoint
+				continue
+			}
+		}
+
+		result = append(result, seg)
+		lastStart = current
+		switch seg.Command {
+		case SvgPathSegTypeMoveToAbs:
+			subpathStart = seg.TargetPoint
+			current = seg.TargetPoint
+		case SvgPathSegTypeClose:
+			current = subpathStart
+		default:
+			current = seg.TargetPoint
+		}
+	}
+	return result
+}
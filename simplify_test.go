@@ -0,0 +1,28 @@
+package pathparsing
+
+import "testing"
+
+func TestMergeCollinear(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"M0,0 L5,0 L10,0", "M0,0 L10,0"},
+		{"M0,0 L5,0 L10,0 L10,10", "M0,0 L10,0 L10,10"},
+		{"M0,0 h5 h5 v5 v5 h-10 z", "M0,0 L10,0 L10,10 L0,10 Z"},
+		{"M0,0 L5,0 L0,0", "M0,0 L5,0 L0,0"},
+		{"M0,0 L5,0 L5,0 L5,5", "M0,0 L5,0 L5,5"},
+		{"M0,0 L5,0 Z L10,0", "M0,0 L5,0 Z L10,0"},
+		{"M0,0 L5,0 C6,0 7,0 8,0 L9,0", "M0,0 L5,0 C6,0 7,0 8,0 L9,0"},
+	}
+	for _, test := range tests {
+		actual := SerializeSegments(MergeCollinear(mustParsePath(t, test.input), 1e-9))
+		if actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.input, test.expected, actual)
+		}
+	}
+
+	if actual := SerializeSegments(MergeCollinear(mustParsePath(t, "M0,0 L10,0 L20,0.1"), 0.02)); actual != "M0,0 L20,0.1" {
+		t.Errorf("expected the slight bend to be merged, got %q", actual)
+	}
+}
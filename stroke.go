@@ -0,0 +1,273 @@
+package pathparsing
+
+import "math"
+
+// LineJoin is the shape drawn where two stroked segments meet, as with
+// SVG's stroke-linejoin.
+type LineJoin int
+
+const (
+	// JoinMiter extends the outer edges of the stroke until they meet.
+	JoinMiter LineJoin = iota
+	// JoinRound rounds the corner with an arc of the stroke's half width.
+	JoinRound
+	// JoinBevel cuts the corner off with a straight line.
+	JoinBevel
+)
+
+// LineCap is the shape drawn at the ends of an open stroked subpath, as with
+// SVG's stroke-linecap.
+type LineCap int
+
+const (
+	// CapButt ends the stroke flush with the end point.
+	CapButt LineCap = iota
+	// CapRound ends the stroke with a half circle around the end point.
+	CapRound
+	// CapSquare ends the stroke with a half square around the end point.
+	CapSquare
+)
+
+// StrokeOptions describes how StrokeOutline strokes a path. The zero value
+// of each style field is SVG's default.
+type StrokeOptions struct {
+	// Width is the stroke width.
+	Width float64
+	Join  LineJoin
+	Cap   LineCap
+	// MiterLimit bounds the length of miter joins, as a multiple of half the
+	// stroke width measured from the vertex to the miter's tip, as SVG's
+	// stroke-miterlimit does. Longer miters fall back to bevel joins. Zero
+	// means SVG's default of 4.
+	MiterLimit float64
+}
+
+// defaultMiterLimit is SVG's initial stroke-miterlimit.
+const defaultMiterLimit = 4
+
+// StrokeOutline returns the outline of the path stroked with the given
+// options, as absolute segments to be filled with the nonzero rule; the
+// contours may overlap each other. The path is flattened first. An open
+// subpath becomes one contour running along one side, around the end cap,
+// back along the other side and around the start cap. A closed subpath
+// becomes two contours of opposite direction, one per side. Round joins and
+// caps are written as arcs. A subpath of zero length gets a dot for round
+// and square caps. A non-positive width gives nil.
+func StrokeOutline(segments []PathSegmentData, options StrokeOptions) []PathSegmentData {
+	if options.Width <= 0 {
+		return nil
+	}
+	if options.MiterLimit == 0 {
+		options.MiterLimit = defaultMiterLimit
+	}
+	s := stroker{options: options, halfWidth: options.Width / 2}
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		s.strokeContour(c)
+	}
+	return s.result
+}
+
+// stroker accumulates the outline of a stroked path.
+type stroker struct {
+	options   StrokeOptions
+	halfWidth float64
+	result    []PathSegmentData
+}
+
+func (s *stroker) moveTo(p PathOffset) {
+	s.result = append(s.result, PathSegmentData{Command: SvgPathSegTypeMoveToAbs, TargetPoint: p})
+}
+
+func (s *stroker) lineTo(p PathOffset) {
+	s.result = append(s.result, PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: p})
+}
+
+// arcTo adds a circular arc of the stroke's half width around center from
+// the current point to p, turning the short way, or through through if it
+// is not nil.
+func (s *stroker) arcTo(center, from, p PathOffset, through *PathOffset) {
+	turnTo := p
+	if through != nil {
+		turnTo = *through
+	}
+	sweep := cross(center, from, turnTo) > 0
+	if through != nil {
+		s.result = append(s.result, s.arc(*through, sweep))
+	}
+	s.result = append(s.result, s.arc(p, sweep))
+}
+
+// arc returns a small circular arc of the stroke's half width to p.
+func (s *stroker) arc(p PathOffset, sweep bool) PathSegmentData {
+	return PathSegmentData{
+		Command:     SvgPathSegTypeArcToAbs,
+		TargetPoint: p,
+		Point1:      PathOffset{s.halfWidth, s.halfWidth},
+		ArcSweep:    sweep,
+	}
+}
+
+func (s *stroker) close() {
+	s.result = append(s.result, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: s.result[len(s.result)-1].TargetPoint})
+}
+
+// strokeContour adds the outline of a flattened subpath.
+func (s *stroker) strokeContour(c contour) {
+	points := c.points
+	if c.closed {
+		points = cleanPolygon(points)
+	} else {
+		points = dedupePoints(points)
+	}
+	switch {
+	case len(points) < 2:
+		if len(c.points) > 1 || c.closed {
+			s.dot(c.points[0])
+		}
+	case c.closed:
+		s.side(points, true)
+		s.close()
+		reversed := make([]PathOffset, len(points))
+		for i, p := range points {
+			reversed[len(points)-1-i] = p
+		}
+		s.side(reversed, true)
+		s.close()
+	default:
+		s.side(points, false)
+		n := len(points)
+		s.cap(points[n-1], points[n-1].Subtract(points[n-2]))
+		reversed := make([]PathOffset, n)
+		for i, p := range points {
+			reversed[n-1-i] = p
+		}
+		s.sideSegments(reversed, false)
+		s.cap(points[0], points[0].Subtract(points[1]))
+		s.close()
+	}
+}
+
+// dedupePoints returns the points without consecutive repeats.
+func dedupePoints(points []PathOffset) []PathOffset {
+	result := points[:1:1]
+	for _, p := range points[1:] {
+		if p != result[len(result)-1] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// edgeNormal returns the unit normal of the edge from a to b on the side
+// the stroker offsets towards, which is to the right of the direction of
+// travel in SVG's y-down coordinate system.
+func edgeNormal(a, b PathOffset) PathOffset {
+	d := b.Subtract(a)
+	length := math.Hypot(d.Dx, d.Dy)
+	return PathOffset{-d.Dy / length, d.Dx / length}
+}
+
+// side starts a contour and adds the offset of the polyline.
+func (s *stroker) side(points []PathOffset, closed bool) {
+	s.moveTo(points[0].Add(edgeNormal(points[0], points[1]).Multiply(s.halfWidth)))
+	s.sideSegments(points, closed)
+}
+
+// sideSegments adds the offset of the polyline after its start point, with
+// joins at its vertices, from a current point already at that start point,
+// such as where side's moveTo or a cap leaves it. For a closed polyline it
+// ends with the join at the first vertex, back at the start point.
+func (s *stroker) sideSegments(points []PathOffset, closed bool) {
+	edges := len(points) - 1
+	if closed {
+		edges = len(points)
+	}
+	for i := 0; i < edges; i++ {
+		v := points[(i+1)%len(points)]
+		n0 := edgeNormal(points[i], v)
+		s.lineTo(v.Add(n0.Multiply(s.halfWidth)))
+		if i+1 < edges || closed {
+			next := points[(i+2)%len(points)]
+			s.join(v, n0, edgeNormal(v, next), next.Subtract(v))
+		}
+	}
+}
+
+// join adds the join at vertex v between an edge with normal n0 and the
+// next edge with normal n1 and direction d1, ending at the start of the
+// next edge's offset.
+func (s *stroker) join(v, n0, n1, d1 PathOffset) {
+	a := v.Add(n0.Multiply(s.halfWidth))
+	b := v.Add(n1.Multiply(s.halfWidth))
+	if a == b {
+		return
+	}
+	if d1.Dx*n0.Dx+d1.Dy*n0.Dy > 0 {
+		// The inner side of the corner: going through the vertex keeps the
+		// outline inside the stroke, and the nonzero rule fills the overlap.
+		s.lineTo(v)
+		s.lineTo(b)
+		return
+	}
+	dot := n0.Dx*n1.Dx + n0.Dy*n1.Dy
+	switch s.options.Join {
+	case JoinRound:
+		if 1+dot <= 1e-9 {
+			// The path turns back on itself, so the join is a half circle
+			// around the end of the incoming edge, which a and b alone do
+			// not pick out.
+			tip := v.Subtract(d1.Multiply(s.halfWidth / math.Hypot(d1.Dx, d1.Dy)))
+			s.arcTo(v, a, b, &tip)
+			return
+		}
+		s.arcTo(v, a, b, nil)
+	case JoinBevel:
+		s.lineTo(b)
+	default:
+		// The miter's tip lies sqrt(2/(1+dot)) half widths from the vertex;
+		// a reversal has no miter and falls back to a bevel.
+		limit := s.options.MiterLimit
+		if 1+dot > 1e-9 && 2/(1+dot) <= limit*limit {
+			s.lineTo(v.Add(n0.Add(n1).Multiply(s.halfWidth / (1 + dot))))
+		}
+		s.lineTo(b)
+	}
+}
+
+// cap adds the cap at end point p of a polyline arriving in direction d,
+// from the current point on one side to the offset on the other side.
+func (s *stroker) cap(p, d PathOffset) {
+	length := math.Hypot(d.Dx, d.Dy)
+	d = d.Multiply(s.halfWidth / length)
+	n := PathOffset{-d.Dy, d.Dx}
+	from := p.Add(n)
+	to := p.Subtract(n)
+	switch s.options.Cap {
+	case CapRound:
+		tip := p.Add(d)
+		s.arcTo(p, from, to, &tip)
+	case CapSquare:
+		s.lineTo(from.Add(d))
+		s.lineTo(to.Add(d))
+		s.lineTo(to)
+	default:
+		s.lineTo(to)
+	}
+}
+
+// dot adds the cap of a subpath of zero length at p.
+func (s *stroker) dot(p PathOffset) {
+	h := s.halfWidth
+	switch s.options.Cap {
+	case CapRound:
+		s.moveTo(p.Translate(h, 0))
+		s.result = append(s.result, s.arc(p.Translate(-h, 0), true), s.arc(p.Translate(h, 0), true))
+		s.close()
+	case CapSquare:
+		s.moveTo(p.Translate(-h, -h))
+		s.lineTo(p.Translate(h, -h))
+		s.lineTo(p.Translate(h, h))
+		s.lineTo(p.Translate(-h, h))
+		s.close()
+	}
+}
@@ -0,0 +1,149 @@
+package pathparsing
+
+import "testing"
+
+func TestStrokeOutlineRoundJoin(t *testing.T) {
+	bend := mustParsePath(t, "M0,0 H10 V10")
+	round := StrokeOutline(bend, StrokeOptions{Width: 2, Join: JoinRound})
+	miter := StrokeOutline(bend, StrokeOptions{Width: 2})
+
+	arcs := 0
+	for _, seg := range round {
+		if seg.Command == SvgPathSegTypeArcToAbs {
+			arcs++
+		}
+	}
+	if arcs != 1 {
+		t.Errorf("expected one arc for the corner, got %d in %v", arcs, round)
+	}
+
+	// (10.6, -0.6) lies within the half width of the corner; (10.8, -0.8)
+	// lies beyond it but inside the square miter.
+	for _, c := range []struct {
+		p            PathOffset
+		round, miter bool
+	}{
+		{PathOffset{5, 0.9}, true, true},
+		{PathOffset{10.6, -0.6}, true, true},
+		{PathOffset{10.8, -0.8}, false, true},
+		{PathOffset{11.2, -1.2}, false, false},
+		{PathOffset{9.5, 0.5}, true, true},
+	} {
+		if got := Contains(round, c.p, false); got != c.round {
+			t.Errorf("round join contains %v: expected %v", c.p, c.round)
+		}
+		if got := Contains(miter, c.p, false); got != c.miter {
+			t.Errorf("miter join contains %v: expected %v", c.p, c.miter)
+		}
+	}
+}
+
+func TestStrokeOutlineCaps(t *testing.T) {
+	line := mustParsePath(t, "M0,0 H10")
+	for _, c := range []struct {
+		cap    LineCap
+		tip    bool
+		corner bool
+	}{
+		{CapButt, false, false},
+		{CapRound, true, false},
+		{CapSquare, true, true},
+	} {
+		outline := StrokeOutline(line, StrokeOptions{Width: 2, Cap: c.cap})
+		for _, end := range []float64{-1, 1} {
+			x := 5 + end*5.5
+			if got := Contains(outline, PathOffset{x, 0}, false); got != c.tip {
+				t.Errorf("cap %d contains (%v, 0): expected %v", c.cap, x, c.tip)
+			}
+			x = 5 + end*5.9
+			if got := Contains(outline, PathOffset{x, 0.9}, false); got != c.corner {
+				t.Errorf("cap %d contains (%v, 0.9): expected %v", c.cap, x, c.corner)
+			}
+		}
+	}
+}
+
+func TestStrokeOutlineLineSegments(t *testing.T) {
+	line := mustParsePath(t, "M0,0 L10,0")
+	for c, expected := range map[LineCap]string{
+		CapButt:   "M0,1 L10,1 L10,-1 L0,-1 L0,1 Z",
+		CapSquare: "M0,1 L10,1 L11,1 L11,-1 L10,-1 L0,-1 L-1,-1 L-1,1 L0,1 Z",
+	} {
+		if actual := SerializeSegments(StrokeOutline(line, StrokeOptions{Width: 2, Cap: c})); actual != expected {
+			t.Errorf("cap %d: expected %q, got %q", c, expected, actual)
+		}
+	}
+}
+
+func TestStrokeOutlineClosed(t *testing.T) {
+	outline := StrokeOutline(mustParsePath(t, "M0,0 H10 V10 H0 Z"), StrokeOptions{Width: 2})
+	if !Contains(outline, PathOffset{-0.9, -0.9}, false) || !Contains(outline, PathOffset{0.9, 5}, false) {
+		t.Errorf("expected the stroke around the square to be filled")
+	}
+	if Contains(outline, PathOffset{5, 5}, false) || Contains(outline, PathOffset{1.1, 1.1}, false) {
+		t.Errorf("expected the inside of the square to be a hole")
+	}
+}
+
+func TestStrokeOutlineDegenerate(t *testing.T) {
+	if got := StrokeOutline(mustParsePath(t, "M0,0 H10"), StrokeOptions{}); got != nil {
+		t.Errorf("expected nil for zero width, got %v", got)
+	}
+	if got := StrokeOutline(mustParsePath(t, "M5,5 Z"), StrokeOptions{Width: 2}); got != nil {
+		t.Errorf("expected nothing for a butt-capped dot, got %v", got)
+	}
+	dot := StrokeOutline(mustParsePath(t, "M5,5 Z"), StrokeOptions{Width: 2, Cap: CapRound})
+	if !Contains(dot, PathOffset{5.6, 5.6}, false) || Contains(dot, PathOffset{5.8, 5.8}, false) {
+		t.Errorf("expected a round dot of radius 1, got %v", dot)
+	}
+}
+
+func TestStrokeOutlineMiterLimit(t *testing.T) {
+	// The corner turns back at about 11.4 degrees, so its miter reaches
+	// about 10 half widths past the vertex at (10,0).
+	acute := mustParsePath(t, "M0,0 H10 L0,2")
+	beveled := StrokeOutline(acute, StrokeOptions{Width: 2})
+	mitered := StrokeOutline(acute, StrokeOptions{Width: 2, MiterLimit: 11})
+
+	tip := PathOffset{14, 0.2}
+	if Contains(beveled, tip, false) {
+		t.Errorf("expected the default limit to bevel the corner, got %v", beveled)
+	}
+	if !Contains(mitered, tip, false) {
+		t.Errorf("expected a limit of 11 to keep the miter, got %v", mitered)
+	}
+	if lo, hi := Bounds(beveled); hi.Dx > 11 || lo.Dx < -1 {
+		t.Errorf("expected the beveled outline to stay near the path, got bounds %v %v", lo, hi)
+	}
+
+	square := StrokeOutline(mustParsePath(t, "M0,0 H10 V10"), StrokeOptions{Width: 2, MiterLimit: 1.5})
+	if !Contains(square, PathOffset{10.8, -0.8}, false) {
+		t.Errorf("expected a right angle to keep its miter under a limit of 1.5")
+	}
+	square = StrokeOutline(mustParsePath(t, "M0,0 H10 V10"), StrokeOptions{Width: 2, MiterLimit: 1.4})
+	if Contains(square, PathOffset{10.8, -0.8}, false) {
+		t.Errorf("expected a right angle to be beveled under a limit of 1.4")
+	}
+}
+
+func TestStrokeOutlineReversal(t *testing.T) {
+	// Turning back by exactly 180° is an outer corner on both sides, so a
+	// round join puts a half circle around the turning point.
+	for _, svg := range []string{"M0,0 L10,0 L0,0", "M0,0 L10,0 Z"} {
+		outline := StrokeOutline(mustParsePath(t, svg), StrokeOptions{Width: 2, Join: JoinRound})
+		for _, p := range []PathOffset{{10.5, 0}, {10.6, 0.6}, {10.6, -0.6}} {
+			if !Contains(outline, p, false) {
+				t.Errorf("%q: expected the round join to cover %v", svg, p)
+			}
+		}
+		if Contains(outline, PathOffset{11.2, 0}, false) {
+			t.Errorf("%q: expected the join to stay within the half width", svg)
+		}
+
+		// A miter is impossible, so it falls back to a bevel.
+		miter := StrokeOutline(mustParsePath(t, svg), StrokeOptions{Width: 2})
+		if Contains(miter, PathOffset{10.5, 0}, false) {
+			t.Errorf("%q: expected a bevel at the reversal", svg)
+		}
+	}
+}
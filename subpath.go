@@ -0,0 +1,240 @@
+package pathparsing
+
+import "math"
+
+// SplitSubpaths splits the segments into subpaths, each starting with a
+// moveTo. The segments are made absolute first (see AbsoluteSegments). When
+// drawing continues after a close without a moveTo, the new subpath is given
+// an explicit moveTo to the point it starts from.
+func SplitSubpaths(segments []PathSegmentData) [][]PathSegmentData {
+	var subpaths [][]PathSegmentData
+	closed := false
+	for _, seg := range AbsoluteSegments(segments) {
+		switch {
+		case seg.Command == SvgPathSegTypeMoveToAbs:
+			subpaths = append(subpaths, []PathSegmentData{seg})
+		case closed || len(subpaths) == 0:
+			start := PathSegmentData{Command: SvgPathSegTypeMoveToAbs}
+			if len(subpaths) > 0 {
+				last := subpaths[len(subpaths)-1]
+				start.TargetPoint = last[len(last)-1].TargetPoint
+			}
+			subpaths = append(subpaths, []PathSegmentData{start, seg})
+		default:
+			subpaths[len(subpaths)-1] = append(subpaths[len(subpaths)-1], seg)
+		}
+		closed = seg.Command == SvgPathSegTypeClose
+	}
+	return subpaths
+}
+
+// SuggestCloses returns the indices, as numbered by SplitSubpaths, of the
+// open subpaths that end within epsilon of where they start and so were
+// probably meant to be closed.
+func SuggestCloses(segments []PathSegmentData, epsilon float64) []int {
+	var indices []int
+	for i, subpath := range SplitSubpaths(segments) {
+		last := subpath[len(subpath)-1]
+		if len(subpath) < 2 || last.Command == SvgPathSegTypeClose {
+			continue
+		}
+		start := subpath[0].TargetPoint
+		if math.Hypot(last.TargetPoint.Dx-start.Dx, last.TargetPoint.Dy-start.Dy) <= epsilon {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// ZeroAreaSubpaths returns the indices, as numbered by SplitSubpaths, of the
+// closed subpaths whose points are all collinear, which paint nothing when
+// filled and can be dropped beforehand. Rounding is allowed for: a subpath
+// counts as collinear when its area is negligible relative to the square of
+// its extent.
+func ZeroAreaSubpaths(segments []PathSegmentData) []int {
+	var indices []int
+	for i, subpath := range SplitSubpaths(segments) {
+		if subpath[len(subpath)-1].Command != SvgPathSegTypeClose {
+			continue
+		}
+		flattened := flattenContours(subpath, DefaultFlattenTolerance)
+		if len(flattened) == 0 {
+			continue
+		}
+		points := flattened[0].points
+		extent := 0.0
+		for _, p := range points {
+			extent = math.Max(extent, math.Hypot(p.Dx-points[0].Dx, p.Dy-points[0].Dy))
+		}
+		if math.Abs(polygonArea(points)) <= 1e-9*extent*extent {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// ReverseSegments returns the path with the direction of every subpath
+// reversed, keeping the order of the subpaths. The result is absolute (see
+// AbsoluteSegments). An open subpath starts at its former end point. A closed
+// subpath keeps its start point and first runs back along its closing line;
+// arcs keep their radii and flags with the sweep flag flipped.
+func ReverseSegments(segments []PathSegmentData) []PathSegmentData {
+	var result []PathSegmentData
+	for _, subpath := range SplitSubpaths(segments) {
+		result = append(result, reverseSubpath(subpath)...)
+	}
+	return result
+}
+
+// ReverseSubpath returns the path with only the subpath at index, as
+// numbered by SplitSubpaths, reversed as ReverseSegments would reverse it.
+// The other subpaths are kept as they are. The result is absolute and, if
+// index is out of range, otherwise unchanged.
+func ReverseSubpath(segments []PathSegmentData, index int) []PathSegmentData {
+	var result []PathSegmentData
+	for i, subpath := range SplitSubpaths(segments) {
+		if i == index {
+			subpath = reverseSubpath(subpath)
+		}
+		result = append(result, subpath...)
+	}
+	return result
+}
+
+// reverseSubpath reverses an absolute subpath starting with a moveTo.
+func reverseSubpath(subpath []PathSegmentData) []PathSegmentData {
+	start := subpath[0].TargetPoint
+	closed := subpath[len(subpath)-1].Command == SvgPathSegTypeClose
+	drawing := subpath[1:]
+	if closed {
+		drawing = drawing[:len(drawing)-1]
+	}
+	end := start
+	if len(drawing) > 0 {
+		end = drawing[len(drawing)-1].TargetPoint
+	}
+
+	result := make([]PathSegmentData, 0, len(subpath)+1)
+	if closed {
+		result = append(result, PathSegmentData{Command: SvgPathSegTypeMoveToAbs, TargetPoint: start})
+		if end != start {
+			result = append(result, PathSegmentData{Command: SvgPathSegTypeLineToAbs, TargetPoint: end})
+		}
+	} else {
+		result = append(result, PathSegmentData{Command: SvgPathSegTypeMoveToAbs, TargetPoint: end})
+	}
+
+	for i := len(drawing) - 1; i >= 0; i-- {
+		seg := drawing[i]
+		previous := start
+		if i > 0 {
+			previous = drawing[i-1].TargetPoint
+		}
+		seg.TargetPoint = previous
+		switch seg.Command {
+		case SvgPathSegTypeCubicToAbs:
+			seg.Point1, seg.Point2 = seg.Point2, seg.Point1
+		case SvgPathSegTypeArcToAbs:
+			seg.ArcSweep = !seg.ArcSweep
+		}
+		result = append(result, seg)
+	}
+
+	if closed {
+		result = append(result, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: start})
+	}
+	return result
+}
+
+// subpathDepths returns, for each flattened subpath, how many of the others
+// contain its first point. Subpaths at an even depth are outer contours and
+// those at an odd depth are holes.
+func subpathDepths(contours []contour) []int {
+	depths := make([]int, len(contours))
+	for i, c := range contours {
+		for j, other := range contours {
+			if j != i && len(c.points) > 0 && windingNumber(other.points, c.points[0]) != 0 {
+				depths[i]++
+			}
+		}
+	}
+	return depths
+}
+
+// NormalizeWinding returns the path with its subpaths reversed as needed so
+// that outer contours run clockwise and holes counter-clockwise in SVG's
+// y-down coordinate system, or the other way around if outerClockwise is not
+// set. A subpath is a hole when it lies inside an odd number of others.
+// Subpaths enclosing no area are kept as they are. The result is absolute.
+func NormalizeWinding(segments []PathSegmentData, outerClockwise bool) []PathSegmentData {
+	subpaths := SplitSubpaths(segments)
+	contours := make([]contour, len(subpaths))
+	for i, subpath := range subpaths {
+		if flattened := flattenContours(subpath, DefaultFlattenTolerance); len(flattened) > 0 {
+			contours[i] = flattened[0]
+		}
+	}
+
+	var result []PathSegmentData
+	for i, depth := range subpathDepths(contours) {
+		area := polygonArea(contours[i].points)
+		wantClockwise := (depth%2 == 0) == outerClockwise
+		if area != 0 && (area > 0) != wantClockwise {
+			result = append(result, reverseSubpath(subpaths[i])...)
+		} else {
+			result = append(result, subpaths[i]...)
+		}
+	}
+	return result
+}
+
+// subpathEnd returns the point an absolute subpath ends at, which for a
+// closed subpath is its start.
+func subpathEnd(subpath []PathSegmentData) PathOffset {
+	return subpath[len(subpath)-1].TargetPoint
+}
+
+// OptimizePlotOrder returns the path with its subpaths reordered to shorten
+// the distance a pen plotter travels between them with the pen up, as
+// measured by TravelProxy. It keeps the first subpath first and then
+// repeatedly picks the subpath whose start is nearest to where the previous
+// one ended, drawing an open subpath in reverse (see ReverseSegments) when
+// its end is nearer. This nearest neighbor heuristic is not optimal but
+// usually shortens travel considerably. The result is absolute.
+func OptimizePlotOrder(segments []PathSegmentData) []PathSegmentData {
+	subpaths := SplitSubpaths(segments)
+	if len(subpaths) == 0 {
+		return nil
+	}
+	used := make([]bool, len(subpaths))
+	used[0] = true
+	result := append([]PathSegmentData(nil), subpaths[0]...)
+	current := subpathEnd(subpaths[0])
+	for range subpaths[1:] {
+		best, reversed := -1, false
+		bestDistance := math.Inf(1)
+		for i, subpath := range subpaths {
+			if used[i] {
+				continue
+			}
+			start := subpath[0].TargetPoint
+			if d := math.Hypot(start.Dx-current.Dx, start.Dy-current.Dy); d < bestDistance {
+				best, reversed, bestDistance = i, false, d
+			}
+			if subpath[len(subpath)-1].Command != SvgPathSegTypeClose {
+				end := subpathEnd(subpath)
+				if d := math.Hypot(end.Dx-current.Dx, end.Dy-current.Dy); d < bestDistance {
+					best, reversed, bestDistance = i, true, d
+				}
+			}
+		}
+		used[best] = true
+		subpath := subpaths[best]
+		if reversed {
+			subpath = reverseSubpath(subpath)
+		}
+		result = append(result, subpath...)
+		current = subpathEnd(subpath)
+	}
+	return result
+}
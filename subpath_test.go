@@ -0,0 +1,115 @@
+package pathparsing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func assertSegmentsEqual(t *testing.T, expected, actual []PathSegmentData) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Errorf("segment %d: expected %v, got %v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestSplitSubpaths(t *testing.T) {
+	subpaths := SplitSubpaths(mustParsePath(t, "M0,0 L10,0 Z L5,5 M20,20 l1,1"))
+	if len(subpaths) != 3 {
+		t.Fatalf("expected 3 subpaths, got %v", subpaths)
+	}
+	assertSegmentsEqual(t, AbsoluteSegments(mustParsePath(t, "M0,0 L5,5")), subpaths[1])
+	assertSegmentsEqual(t, AbsoluteSegments(mustParsePath(t, "M20,20 L21,21")), subpaths[2])
+}
+
+func TestSuggestCloses(t *testing.T) {
+	segments := mustParsePath(t, "M0,0 H10 V10 H0 V0 M20,0 H30 V10 H20.001 V0.001 Z M40,0 h10 v10 M60,0 h10 v10 h-10 v-9.999")
+	if actual := SuggestCloses(segments, 0.01); !reflect.DeepEqual(actual, []int{0, 3}) {
+		t.Errorf("expected [0 3], got %v", actual)
+	}
+	if actual := SuggestCloses(segments, 0); !reflect.DeepEqual(actual, []int{0}) {
+		t.Errorf("expected [0], got %v", actual)
+	}
+}
+
+func TestZeroAreaSubpaths(t *testing.T) {
+	segments := mustParsePath(t, "M0,0 L5,5 L10,10 Z M20,0 L30,0 L25,5 Z M40,0 C41,1 42,2 43,3 Z M50,0 L60,0 L55,0 M70,70 Z")
+	if actual := ZeroAreaSubpaths(segments); !reflect.DeepEqual(actual, []int{0, 2, 4}) {
+		t.Errorf("expected [0 2 4], got %v", actual)
+	}
+}
+
+func TestReverseSegments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"M0,0 L10,0 C10,5 5,10 0,10", "M0,10 C5,10 10,5 10,0 L0,0"},
+		{"M0,0 H10 V10 Z", "M0,0 L10,10 L10,0 L0,0 Z"},
+		{"M0,0 Q5,5 10,0 A5,5 0 0 1 20,0", "M20,0 A5,5 0 0 0 10,0 Q5,5 0,0"},
+		{"M0,0 L1,0 M5,5 L6,6", "M1,0 L0,0 M6,6 L5,5"},
+	}
+	for _, test := range tests {
+		expected := AbsoluteSegments(mustParsePath(t, test.expected))
+		assertSegmentsEqual(t, expected, ReverseSegments(mustParsePath(t, test.input)))
+	}
+}
+
+func TestReverseSubpath(t *testing.T) {
+	segments := mustParsePath(t, "M0,0 H10 V10 Z M20,0 L30,0 C35,5 35,10 30,10")
+	expected := "M0,0 L10,0 L10,10 Z M30,10 C35,10 35,5 30,0 L20,0"
+	if actual := SerializeSegments(ReverseSubpath(segments, 1)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	expected = "M0,0 L10,0 L10,10 Z M20,0 L30,0 C35,5 35,10 30,10"
+	if actual := SerializeSegments(ReverseSubpath(segments, 2)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestNormalizeWinding(t *testing.T) {
+	donut := mustParsePath(t, "M0,0 V10 H10 V0 Z M3,3 H7 V7 H3 Z")
+	for _, outerClockwise := range []bool{true, false} {
+		subpaths := SplitSubpaths(NormalizeWinding(donut, outerClockwise))
+		if len(subpaths) != 2 {
+			t.Fatalf("expected 2 subpaths, got %v", subpaths)
+		}
+		outer := SignedArea(subpaths[0])
+		inner := SignedArea(subpaths[1])
+		if (outer > 0) != outerClockwise || (inner > 0) == outerClockwise {
+			t.Errorf("outerClockwise %v: unexpected areas %v and %v", outerClockwise, outer, inner)
+		}
+		assertClose(t, "filled area", 84, FilledArea(NormalizeWinding(donut, outerClockwise), false), 1e-9)
+	}
+}
+
+func TestOptimizePlotOrder(t *testing.T) {
+	segments := mustParsePath(t, "M0,0 H10 M100,0 H110 M30,0 H20")
+	optimized := OptimizePlotOrder(segments)
+	expected := "M0,0 L10,0 M20,0 L30,0 M100,0 L110,0"
+	if actual := SerializeSegments(optimized); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	travel := func(segments []PathSegmentData) float64 {
+		proxy := NewTravelProxy()
+		WriteSegmentsToPath(segments, proxy)
+		return proxy.Travel()
+	}
+	if before, after := travel(segments), travel(optimized); before != 170 || after != 80 {
+		t.Errorf("expected travel to drop from 170 to 80, got %v and %v", before, after)
+	}
+
+	// Closed subpaths end where they start, so they are never reversed.
+	expected = "M0,0 L1,0 M5,0 L5,5 L8,5 Z M9,0 L20,0"
+	if actual := SerializeSegments(OptimizePlotOrder(mustParsePath(t, "M0,0 H1 M20,0 H9 M5,0 V5 H8 Z"))); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if actual := OptimizePlotOrder(nil); actual != nil {
+		t.Errorf("expected nil, got %v", actual)
+	}
+}
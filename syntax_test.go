@@ -1,6 +1,10 @@
 package pathparsing
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 type TestPathProxy struct {
 	called bool
@@ -188,3 +192,116 @@ func TestMissingCommandsNumbersFlags(t *testing.T) {
 	assertInvalidPath("M0,0 A10,10 0 0,# 20,20")
 	assertInvalidPath("M0,0 A10,10 0 0,2 20,20")
 }
+
+func TestTruncatedNumbers(t *testing.T) {
+	tests := []struct {
+		input   string
+		offset  int
+		message string
+	}{
+		{"M0,0 L+", 7, "unexpected end of path data: expected a digit or '.' after the sign at offset 7"},
+		{"M0,0 L-", 7, "unexpected end of path data: expected a digit or '.' after the sign at offset 7"},
+		{"M0,0 L.", 7, "unexpected end of path data: there must be at least one digit following the decimal point at offset 7"},
+		{"M0,0 L1.", 8, "unexpected end of path data: there must be at least one digit following the decimal point at offset 8"},
+		{"M0,0 L1,", 8, "unexpected end of path data: expected a number at offset 8"},
+		{"M0,0 L1e+", 9, "unexpected end of path data: missing exponent at offset 9"},
+		{"M0,0 L1e", 8, "unexpected end of path data: missing exponent at offset 8"},
+		{"M0,0 L+x", 7, "expected a digit or '.' after the sign at offset 7"},
+		{"M0,0 L1.x", 8, "there must be at least one digit following the decimal point at offset 8"},
+	}
+	for _, test := range tests {
+		err := WriteSvgPathDataToPath(test.input, &TestPathProxy{})
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%q: expected a *ParseError, got %v", test.input, err)
+			continue
+		}
+		if parseErr.Offset != test.offset || parseErr.Error() != test.message {
+			t.Errorf("%q: expected %q, got %q", test.input, test.message, parseErr.Error())
+		}
+	}
+}
+
+func TestNoneKeyword(t *testing.T) {
+	for _, input := range []string{"none", " NONE ", "None\n"} {
+		proxy := TestPathProxy{}
+		if err := WriteSvgPathDataToPath(input, &proxy); err != nil {
+			t.Errorf("%q: unexpected error %v", input, err)
+		}
+		if proxy.called {
+			t.Errorf("%q: expected no commands", input)
+		}
+		if segments, err := ParsePath(input); err != nil || len(segments) != 0 {
+			t.Errorf("%q: expected no segments, got %v, %v", input, segments, err)
+		}
+	}
+	assertInvalidPath("nonex")
+	assertInvalidPath("M0,0 none")
+}
+
+func TestSegmentsWrittenBeforeError(t *testing.T) {
+	proxy := NewDeepTestPathProxy([]string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(10.0000, 0.0000)",
+	})
+	err := WriteSvgPathDataToPath("M0,0 L10,0 BADCMD", proxy)
+	parseErr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %v", err)
+	}
+	if parseErr.Segments != 2 {
+		t.Errorf("expected 2 segments before the error, got %d", parseErr.Segments)
+	}
+	proxy.Validate()
+}
+
+func TestMissingMoveToError(t *testing.T) {
+	err := WriteSvgPathDataToPath("l10,10", &TestPathProxy{})
+	if !errors.Is(err, ErrMissingMoveTo) {
+		t.Fatalf("expected ErrMissingMoveTo, got %v", err)
+	}
+	if expected := "expected to find moveTo command, found 'l' at offset 0"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+
+	err = WriteSvgPathDataToPath("x10,10", &TestPathProxy{})
+	if err == nil || errors.Is(err, ErrMissingMoveTo) {
+		t.Errorf("expected an unknown command error, got %v", err)
+	}
+}
+
+func TestParseErrorKinds(t *testing.T) {
+	tests := []struct {
+		input  string
+		kind   error
+		offset int
+	}{
+		{"M1e39,0", ErrNumericOverflow, 1},
+		{"M0,0 L1e-40,0", ErrNumericOverflow, 6},
+		{"M0,0 L1" + strings.Repeat("0", 310), ErrNumericOverflow, 6},
+		{"M0,0 L1e,0", ErrMissingExponent, 8},
+		{"M0,0 L1e", ErrMissingExponent, 8},
+		{"M0,0 L-x,0", ErrInvalidNumber, 7},
+		{"M0,0 L1.,0", ErrInvalidNumber, 8},
+		{"M0,0 L10", ErrInvalidNumber, 8},
+		{"M0,0 A5,5 0 2 0 10,0", ErrInvalidFlag, 12},
+		{"M0,0 A5,5 0 1", ErrInvalidFlag, 13},
+	}
+	kinds := []error{ErrNumericOverflow, ErrInvalidNumber, ErrMissingExponent, ErrInvalidFlag, ErrMissingMoveTo}
+	for _, test := range tests {
+		err := WriteSvgPathDataToPath(test.input, &TestPathProxy{})
+		for _, kind := range kinds {
+			if errors.Is(err, kind) != (kind == test.kind) {
+				t.Errorf("%q: errors.Is(%v, %v) = %v", test.input, err, kind, !(kind == test.kind))
+			}
+		}
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) && parseErr.Offset != test.offset {
+			t.Errorf("%q: expected offset %d, got %d", test.input, test.offset, parseErr.Offset)
+		}
+	}
+
+	if err := WriteSvgPathDataToPath("M0,0 L10,0 Q", &TestPathProxy{}); errors.Is(err, ErrInvalidFlag) || errors.Is(err, ErrMissingMoveTo) {
+		t.Errorf("expected an unclassified error to match no sentinel, got %v", err)
+	}
+}
@@ -0,0 +1,390 @@
+package pathparsing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Affine is a 2D affine transform laid out like the SVG matrix(a b c d e f)
+// function, mapping (x, y) to (A*x + C*y + E, B*x + D*y + F).
+type Affine struct {
+	A, B, C, D, E, F float64
+}
+
+// IdentityAffine returns the identity transform.
+func IdentityAffine() Affine {
+	return Affine{1, 0, 0, 1, 0, 0}
+}
+
+// TranslateAffine returns a transform translating by (tx, ty).
+func TranslateAffine(tx, ty float64) Affine {
+	return Affine{1, 0, 0, 1, tx, ty}
+}
+
+// ScaleAffine returns a transform scaling by sx horizontally and sy vertically.
+func ScaleAffine(sx, sy float64) Affine {
+	return Affine{sx, 0, 0, sy, 0, 0}
+}
+
+// RotateAffine returns a transform rotating by the given angle in radians.
+func RotateAffine(angle float64) Affine {
+	sin, cos := math.Sincos(angle)
+	return Affine{cos, sin, -sin, cos, 0, 0}
+}
+
+// SkewXAffine returns a transform skewing along the x axis by the given
+// angle in radians, like the SVG skewX function.
+func SkewXAffine(angle float64) Affine {
+	return Affine{1, 0, math.Tan(angle), 1, 0, 0}
+}
+
+// SkewYAffine returns a transform skewing along the y axis by the given
+// angle in radians, like the SVG skewY function.
+func SkewYAffine(angle float64) Affine {
+	return Affine{1, math.Tan(angle), 0, 1, 0, 0}
+}
+
+// Multiply returns the transform that applies other first and then t.
+func (t Affine) Multiply(other Affine) Affine {
+	return Affine{
+		A: t.A*other.A + t.C*other.B,
+		B: t.B*other.A + t.D*other.B,
+		C: t.A*other.C + t.C*other.D,
+		D: t.B*other.C + t.D*other.D,
+		E: t.A*other.E + t.C*other.F + t.E,
+		F: t.B*other.E + t.D*other.F + t.F,
+	}
+}
+
+// Determinant returns the determinant of the linear part of the transform.
+func (t Affine) Determinant() float64 {
+	return t.A*t.D - t.B*t.C
+}
+
+// Apply returns the point mapped by the transform.
+func (t Affine) Apply(p PathOffset) PathOffset {
+	return PathOffset{t.A*p.Dx + t.C*p.Dy + t.E, t.B*p.Dx + t.D*p.Dy + t.F}
+}
+
+// String returns a string representation of the Affine.
+func (t Affine) String() string {
+	return fmt.Sprintf("Affine{%f,%f,%f,%f,%f,%f}", t.A, t.B, t.C, t.D, t.E, t.F)
+}
+
+// TransformSegments returns the segments mapped by the transform. The
+// segments are made absolute first (see AbsoluteSegments), so the result only
+// contains M, L, C, Q, A and Z commands. Arcs stay arcs: their radii and
+// x-axis rotation are recomputed from the transformed ellipse, which keeps
+// them exact under non-uniform scaling and skewing.
+func TransformSegments(segments []PathSegmentData, t Affine) []PathSegmentData {
+	result := AbsoluteSegments(segments)
+	for i := range result {
+		result[i] = transformSegment(result[i], t)
+	}
+	return result
+}
+
+// transformSegment maps an absolute segment by the transform.
+func transformSegment(seg PathSegmentData, t Affine) PathSegmentData {
+	switch seg.Command {
+	case SvgPathSegTypeArcToAbs:
+		seg = transformArc(seg, t)
+	case SvgPathSegTypeCubicToAbs:
+		seg.Point1 = t.Apply(seg.Point1)
+		seg.Point2 = t.Apply(seg.Point2)
+	case SvgPathSegTypeQuadToAbs:
+		seg.Point1 = t.Apply(seg.Point1)
+	}
+	seg.TargetPoint = t.Apply(seg.TargetPoint)
+	return seg
+}
+
+// transformArc recomputes the radii, rotation and sweep of an arc segment so
+// it describes the image of its ellipse under the linear part of t. The
+// ellipse is the unit circle mapped by M = L * R(angle) * diag(rx, ry); a
+// singular value decomposition M = R(phi) * diag(s1, s2) * R(theta) yields
+// the new radii s1, |s2| and rotation phi. A mirroring transform reverses
+// the direction of travel, so the sweep flag flips. The target point is left
+// for the caller to map.
+func transformArc(seg PathSegmentData, t Affine) PathSegmentData {
+	if t.A == 1 && t.B == 0 && t.C == 0 && t.D == 1 {
+		// Translations leave the ellipse unchanged; skip the decomposition
+		// so the arc parameters round-trip exactly.
+		return seg
+	}
+	rx := math.Abs(seg.Point1.Dx)
+	ry := math.Abs(seg.Point1.Dy)
+	sin, cos := math.Sincos(math.Pi * seg.ArcAngle / 180.0)
+
+	m00 := (t.A*cos + t.C*sin) * rx
+	m01 := (-t.A*sin + t.C*cos) * ry
+	m10 := (t.B*cos + t.D*sin) * rx
+	m11 := (-t.B*sin + t.D*cos) * ry
+
+	e := (m00 + m11) / 2
+	f := (m00 - m11) / 2
+	g := (m10 + m01) / 2
+	h := (m10 - m01) / 2
+	q := math.Hypot(e, h)
+	r := math.Hypot(f, g)
+
+	seg.Point1 = PathOffset{q + r, math.Abs(q - r)}
+	seg.ArcAngle = (math.Atan2(h, e) + math.Atan2(g, f)) / 2 * 180.0 / math.Pi
+	if t.Determinant() < 0 {
+		seg.ArcSweep = !seg.ArcSweep
+	}
+	return seg
+}
+
+// SkewSegments returns the segments skewed along the x axis by angleX and
+// then along the y axis by angleY, both in radians, as the SVG transform
+// "skewY(angleY) skewX(angleX)" does. It is TransformSegments with the
+// composed skew, so arcs stay exact arcs.
+func SkewSegments(segments []PathSegmentData, angleX, angleY float64) []PathSegmentData {
+	return TransformSegments(segments, SkewYAffine(angleY).Multiply(SkewXAffine(angleX)))
+}
+
+// TranslateSegments returns the segments shifted by (dx, dy). Only absolute
+// coordinates are offset; relative commands are left unchanged because they
+// are relative to a current point that moves with the rest of the path. A
+// leading relative moveTo is measured from the origin, so it is offset too.
+// Arc radii and rotation are unaffected by a translation.
+func TranslateSegments(segments []PathSegmentData, dx, dy float64) []PathSegmentData {
+	result := make([]PathSegmentData, len(segments))
+	for i, seg := range segments {
+		switch seg.Command {
+		case SvgPathSegTypeCubicToAbs:
+			seg.Point1 = seg.Point1.Translate(dx, dy)
+			fallthrough
+		case SvgPathSegTypeSmoothCubicToAbs:
+			seg.Point2 = seg.Point2.Translate(dx, dy)
+			seg.TargetPoint = seg.TargetPoint.Translate(dx, dy)
+		case SvgPathSegTypeQuadToAbs:
+			seg.Point1 = seg.Point1.Translate(dx, dy)
+			seg.TargetPoint = seg.TargetPoint.Translate(dx, dy)
+		case SvgPathSegTypeMoveToAbs, SvgPathSegTypeLineToAbs, SvgPathSegTypeSmoothQuadToAbs, SvgPathSegTypeArcToAbs:
+			seg.TargetPoint = seg.TargetPoint.Translate(dx, dy)
+		case SvgPathSegTypeLineToHorizontalAbs:
+			seg.TargetPoint = seg.TargetPoint.Translate(dx, 0)
+		case SvgPathSegTypeLineToVerticalAbs:
+			seg.TargetPoint = seg.TargetPoint.Translate(0, dy)
+		case SvgPathSegTypeMoveToRel:
+			if i == 0 {
+				seg.TargetPoint = seg.TargetPoint.Translate(dx, dy)
+			}
+		}
+		result[i] = seg
+	}
+	return result
+}
+
+// TransformProxy maps every coordinate by an affine transform before
+// forwarding it to another PathProxy. Cubic Béziers are closed under affine
+// maps, so the output is exact. It keeps no per-segment state and allocates
+// nothing while forwarding.
+type TransformProxy struct {
+	path      PathProxy
+	transform Affine
+}
+
+// NewTransformProxy creates a TransformProxy forwarding to path.
+func NewTransformProxy(path PathProxy, transform Affine) *TransformProxy {
+	return &TransformProxy{
+		path:      path,
+		transform: transform,
+	}
+}
+
+// MoveTo forwards a transformed move command.
+func (p *TransformProxy) MoveTo(x, y float64) {
+	pt := p.transform.Apply(PathOffset{x, y})
+	p.path.MoveTo(pt.Dx, pt.Dy)
+}
+
+// LineTo forwards a transformed line command.
+func (p *TransformProxy) LineTo(x, y float64) {
+	pt := p.transform.Apply(PathOffset{x, y})
+	p.path.LineTo(pt.Dx, pt.Dy)
+}
+
+// CubicTo forwards a transformed cubic command.
+func (p *TransformProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p1 := p.transform.Apply(PathOffset{x1, y1})
+	p2 := p.transform.Apply(PathOffset{x2, y2})
+	p3 := p.transform.Apply(PathOffset{x3, y3})
+	p.path.CubicTo(p1.Dx, p1.Dy, p2.Dx, p2.Dy, p3.Dx, p3.Dy)
+}
+
+// Close forwards a close command.
+func (p *TransformProxy) Close() {
+	p.path.Close()
+}
+
+// NormalizeProxy forwards commands to another PathProxy with coordinates
+// mapped into the unit square, for comparing shapes independently of their
+// position and size. It is constructed from the path's bounds, for example
+// from Bounds: the bounds are translated to the origin and uniformly scaled
+// so their longer side has length one, keeping the aspect ratio. Points
+// drawn map into [0,1]×[0,1], though cubic control points may lie outside.
+// Bounds of zero extent map everything to the origin.
+type NormalizeProxy struct {
+	transform TransformProxy
+}
+
+// NewNormalizeProxy creates a NormalizeProxy forwarding to path and mapping
+// the bounds with corners lo and hi into the unit square.
+func NewNormalizeProxy(path PathProxy, lo, hi PathOffset) *NormalizeProxy {
+	scale := 0.0
+	if extent := math.Max(hi.Dx-lo.Dx, hi.Dy-lo.Dy); extent > 0 {
+		scale = 1 / extent
+	}
+	t := ScaleAffine(scale, scale).Multiply(TranslateAffine(-lo.Dx, -lo.Dy))
+	return &NormalizeProxy{transform: TransformProxy{path: path, transform: t}}
+}
+
+// MoveTo forwards a normalized move command.
+func (p *NormalizeProxy) MoveTo(x, y float64) {
+	p.transform.MoveTo(x, y)
+}
+
+// LineTo forwards a normalized line command.
+func (p *NormalizeProxy) LineTo(x, y float64) {
+	p.transform.LineTo(x, y)
+}
+
+// CubicTo forwards a normalized cubic command.
+func (p *NormalizeProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.transform.CubicTo(x1, y1, x2, y2, x3, y3)
+}
+
+// Close forwards a close command.
+func (p *NormalizeProxy) Close() {
+	p.transform.Close()
+}
+
+// WriteSvgPathDataTransformed writes SVG path data to the given path with
+// every coordinate mapped by the transform. Segments are parsed, normalized
+// and transformed one at a time, so the path is never buffered as a whole
+// and the number of allocations does not depend on its length.
+func WriteSvgPathDataTransformed(svg string, t Affine, path PathProxy) error {
+	return WriteSvgPathDataToPath(svg, NewTransformProxy(path, t))
+}
+
+// WriteSvgPathDataToPathScaled writes SVG path data to the given path with
+// every coordinate multiplied by scale, for example to convert user units at
+// 96 DPI to points with a scale of 0.75.
+func WriteSvgPathDataToPathScaled(svg string, path PathProxy, scale float64) error {
+	return WriteSvgPathDataTransformed(svg, ScaleAffine(scale, scale), path)
+}
+
+// affineFromMat4 returns the transform of the xy plane by the matrix, as
+// mapPoint applies it, ignoring z and perspective.
+func affineFromMat4(m mgl32.Mat4) Affine {
+	return Affine{
+		A: float64(m[0]), B: float64(m[1]),
+		C: float64(m[4]), D: float64(m[5]),
+		E: float64(m[12]), F: float64(m[13]),
+	}
+}
+
+// WriteSvgPathDataToPathTransform writes SVG path data to the given path
+// with every coordinate mapped by the xy part of the matrix, as
+// WriteSvgPathDataTransformed does for an Affine. Arcs are transformed
+// exactly as their cubic approximations are mapped point by point.
+func WriteSvgPathDataToPathTransform(svg string, path PathProxy, m mgl32.Mat4) error {
+	return WriteSvgPathDataTransformed(svg, affineFromMat4(m), path)
+}
+
+// ParseWithLeadingTransform writes SVG path data preceded by transform
+// functions, such as "translate(10,10) M0,0 L5,5", to the given path with
+// the transforms applied, for inputs that put a transform attribute's value
+// in front of the path data. translate, scale and rotate are recognized,
+// with the optional arguments of the SVG transform attribute and angles in
+// degrees; several functions compose as in that attribute, the rightmost
+// applying first. Data without a leading function is written unchanged.
+// Errors are *ParseError values with offsets into s.
+func ParseWithLeadingTransform(s string, path PathProxy) error {
+	t, rest, err := parseLeadingTransform(s)
+	if err != nil {
+		return err
+	}
+	if err := WriteSvgPathDataTransformed(s[rest:], t, path); err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.Offset += rest
+		}
+		return err
+	}
+	return nil
+}
+
+// parseLeadingTransform parses the transform functions at the start of s,
+// returning their combined transform and the offset where the path data
+// starts.
+func parseLeadingTransform(s string) (Affine, int, error) {
+	t := IdentityAffine()
+	i := 0
+	for {
+		for i < len(s) && (isXMLWhitespace(s[i]) || s[i] == ',') {
+			i++
+		}
+		nameEnd := i
+		for nameEnd < len(s) && (s[nameEnd] >= 'a' && s[nameEnd] <= 'z' || s[nameEnd] >= 'A' && s[nameEnd] <= 'Z') {
+			nameEnd++
+		}
+		open := nameEnd
+		for open < len(s) && isXMLWhitespace(s[open]) {
+			open++
+		}
+		if nameEnd == i || open == len(s) || s[open] != '(' {
+			return t, i, nil
+		}
+		end := strings.IndexByte(s[open:], ')')
+		if end < 0 {
+			return t, i, &ParseError{Offset: open, Msg: "unterminated transform function"}
+		}
+		end += open
+		var args []float64
+		for _, field := range strings.FieldsFunc(s[open+1:end], func(r rune) bool { return strings.ContainsRune(", \t\n\r", r) }) {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return t, i, &ParseError{Offset: open + 1, Msg: fmt.Sprintf("invalid transform argument %q", field)}
+			}
+			args = append(args, v)
+		}
+		fn, ok := transformFunction(s[i:nameEnd], args)
+		if !ok {
+			return t, i, &ParseError{Offset: i, Msg: fmt.Sprintf("unsupported transform %s with %d arguments", s[i:nameEnd], len(args))}
+		}
+		t = t.Multiply(fn)
+		i = end + 1
+	}
+}
+
+// transformFunction returns the transform of a translate, scale or rotate
+// function with the given arguments, or false if the name or argument count
+// is not supported.
+func transformFunction(name string, args []float64) (Affine, bool) {
+	switch {
+	case name == "translate" && len(args) == 1:
+		return TranslateAffine(args[0], 0), true
+	case name == "translate" && len(args) == 2:
+		return TranslateAffine(args[0], args[1]), true
+	case name == "scale" && len(args) == 1:
+		return ScaleAffine(args[0], args[0]), true
+	case name == "scale" && len(args) == 2:
+		return ScaleAffine(args[0], args[1]), true
+	case name == "rotate" && len(args) == 1:
+		return RotateAffine(args[0] * math.Pi / 180), true
+	case name == "rotate" && len(args) == 3:
+		center := TranslateAffine(args[1], args[2])
+		back := TranslateAffine(-args[1], -args[2])
+		return center.Multiply(RotateAffine(args[0] * math.Pi / 180)).Multiply(back), true
+	}
+	return Affine{}, false
+}
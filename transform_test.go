@@ -0,0 +1,287 @@
+package pathparsing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type pointRecordingPathProxy struct {
+	points []PathOffset
+}
+
+func (p *pointRecordingPathProxy) MoveTo(x, y float64) {
+	p.points = append(p.points, PathOffset{x, y})
+}
+
+func (p *pointRecordingPathProxy) LineTo(x, y float64) {
+	p.points = append(p.points, PathOffset{x, y})
+}
+
+func (p *pointRecordingPathProxy) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	p.points = append(p.points, PathOffset{x1, y1}, PathOffset{x2, y2}, PathOffset{x3, y3})
+}
+
+func (p *pointRecordingPathProxy) Close() {
+}
+
+func assertPointsClose(t *testing.T, expected, actual []PathOffset, tolerance float64) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %d points, got %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		if math.Abs(expected[i].Dx-actual[i].Dx) > tolerance || math.Abs(expected[i].Dy-actual[i].Dy) > tolerance {
+			t.Errorf("point %d: expected %v, got %v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestTransformSegmentsArc(t *testing.T) {
+	transforms := []Affine{
+		ScaleAffine(2, 1),
+		ScaleAffine(-1, 3),
+		RotateAffine(math.Pi / 5).Multiply(ScaleAffine(1, 0.5)),
+		{1, 0, 1, 1, 4, -2},
+	}
+	segments, err := ParsePath("M10,10 A30,20 30 0 1 50,30 a15,25 -20 1 0 -10,20")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, transform := range transforms {
+		reference := pointRecordingPathProxy{}
+		WriteSegmentsToPath(segments, &reference)
+		for i, p := range reference.points {
+			reference.points[i] = transform.Apply(p)
+		}
+
+		transformed := TransformSegments(segments, transform)
+		if transformed[1].Command != SvgPathSegTypeArcToAbs || transformed[2].Command != SvgPathSegTypeArcToAbs {
+			t.Fatalf("%v: arcs were not preserved: %v", transform, transformed)
+		}
+		actual := pointRecordingPathProxy{}
+		WriteSegmentsToPath(transformed, &actual)
+		assertPointsClose(t, reference.points, actual.points, 1e-3)
+	}
+}
+
+func TestSkewSegments(t *testing.T) {
+	skewed := SkewSegments(mustParsePath(t, "M0,0 H10 V10 H0 Z"), math.Pi/4, 0)
+	expected := []PathOffset{{0, 0}, {10, 0}, {20, 10}, {10, 10}, {0, 0}}
+	actual := make([]PathOffset, len(skewed))
+	for i, seg := range skewed {
+		actual[i] = seg.TargetPoint
+	}
+	assertPointsClose(t, expected, actual, 1e-9)
+	assertClose(t, "parallelogram area", 100, FilledArea(skewed, false), 1e-9)
+
+	skewed = SkewSegments(mustParsePath(t, "M0,0 V10"), 0, math.Pi/4)
+	assertPointsClose(t, []PathOffset{{0, 10}}, []PathOffset{skewed[1].TargetPoint}, 1e-9)
+
+	// A skewed circle becomes a tilted ellipse of the same area.
+	circle := mustParsePath(t, "M0,5 A5,5 0 0 1 10,5 A5,5 0 0 1 0,5 Z")
+	skewed = SkewSegments(circle, math.Pi/4, 0)
+	if skewed[1].Point1.Dx == skewed[1].Point1.Dy || skewed[1].ArcAngle == 0 {
+		t.Errorf("expected a rotated ellipse, got %v", skewed[1])
+	}
+	assertClose(t, "ellipse area", FilledArea(circle, false), FilledArea(skewed, false), 0.1)
+}
+
+func TestAbsoluteSegments(t *testing.T) {
+	segments, err := ParsePath("m1,2 h3 v4 s1,1 2,2 t1,1 z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PathSegmentData{
+		{Command: SvgPathSegTypeMoveToAbs, TargetPoint: PathOffset{1, 2}},
+		{Command: SvgPathSegTypeLineToAbs, TargetPoint: PathOffset{4, 2}},
+		{Command: SvgPathSegTypeLineToAbs, TargetPoint: PathOffset{4, 6}},
+		{Command: SvgPathSegTypeCubicToAbs, TargetPoint: PathOffset{6, 8}, Point1: PathOffset{4, 6}, Point2: PathOffset{5, 7}},
+		{Command: SvgPathSegTypeQuadToAbs, TargetPoint: PathOffset{7, 9}, Point1: PathOffset{6, 8}},
+		{Command: SvgPathSegTypeClose, TargetPoint: PathOffset{1, 2}},
+	}
+	actual := AbsoluteSegments(segments)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("segment %d: expected %v, got %v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestTranslateSegments(t *testing.T) {
+	segments, err := ParsePath("M0,0 L10,0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated := TranslateSegments(segments, 5, 5)
+	if translated[0].TargetPoint != (PathOffset{5, 5}) || translated[1].TargetPoint != (PathOffset{15, 5}) {
+		t.Errorf("unexpected translation: %v", translated)
+	}
+
+	segments, err = ParsePath("m1,1 h4 V3 l-2,2 A1,1 0 0 1 0,4 z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := pointRecordingPathProxy{}
+	WriteSegmentsToPath(segments, &reference)
+	for i, p := range reference.points {
+		reference.points[i] = p.Translate(-3, 7)
+	}
+	actual := pointRecordingPathProxy{}
+	WriteSegmentsToPath(TranslateSegments(segments, -3, 7), &actual)
+	assertPointsClose(t, reference.points, actual.points, 1e-4)
+}
+
+func TestWriteSvgPathDataTransformed(t *testing.T) {
+	proxy := NewDeepTestPathProxy([]string{
+		"moveTo(12.0000, 3.0000)",
+		"lineTo(32.0000, 3.0000)",
+		"cubicTo(34.0000, 4.0000, 36.0000, 5.0000, 38.0000, 6.0000)",
+		"close()",
+	})
+	err := WriteSvgPathDataTransformed("M1,1 L11,1 C12,2 13,3 14,4 Z", TranslateAffine(10, 2).Multiply(ScaleAffine(2, 1)), proxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy.Validate()
+}
+
+func TestWriteSvgPathDataToPathScaled(t *testing.T) {
+	proxy := NewDeepTestPathProxy([]string{
+		"moveTo(10.0000, 10.0000)",
+		"lineTo(20.0000, 20.0000)",
+	})
+	if err := WriteSvgPathDataToPathScaled("M1,1 L2,2", proxy, 10); err != nil {
+		t.Fatal(err)
+	}
+	proxy.Validate()
+}
+
+func TestWriteSvgPathDataToPathTransform(t *testing.T) {
+	svg := "M1,1 L11,1 A5,5 0 0 1 21,1 Z"
+	reference := NewRecordingProxy()
+	if err := WriteSvgPathDataToPath(svg, reference); err != nil {
+		t.Fatal(err)
+	}
+	identity := NewRecordingProxy()
+	if err := WriteSvgPathDataToPathTransform(svg, identity, mgl32.Ident4()); err != nil {
+		t.Fatal(err)
+	}
+	if err := identity.Diff(reference.Commands()); err != nil {
+		t.Error(err)
+	}
+
+	proxy := NewDeepTestPathProxy([]string{
+		"moveTo(12.0000, 3.0000)",
+		"lineTo(32.0000, 3.0000)",
+		"cubicTo(32.0000, 0.2386, 36.4772, -2.0000, 42.0000, -2.0000)",
+		"cubicTo(47.5228, -2.0000, 52.0000, 0.2386, 52.0000, 3.0000)",
+		"close()",
+	})
+	m := mgl32.Translate3D(10, 2, 0).Mul4(mgl32.Scale3D(2, 1, 1))
+	if err := WriteSvgPathDataToPathTransform("M1,1 L11,1 A5,5 0 0 1 21,1 Z", proxy, m); err != nil {
+		t.Fatal(err)
+	}
+	proxy.Validate()
+}
+
+func repeatedPathData(n int) string {
+	svg := "M0,0"
+	for i := 0; i < n; i++ {
+		svg += " L10,10 C1,2 3,4 5,6 A5,5 0 0 1 15,15"
+	}
+	return svg + " Z"
+}
+
+func TestWriteSvgPathDataTransformedAllocations(t *testing.T) {
+	transform := RotateAffine(1).Multiply(ScaleAffine(2, 3))
+	short := repeatedPathData(1)
+	long := repeatedPathData(1000)
+	allocs := func(svg string) float64 {
+		return testing.AllocsPerRun(10, func() {
+			WriteSvgPathDataTransformed(svg, transform, &countingPathProxy{})
+		})
+	}
+	if shortAllocs, longAllocs := allocs(short), allocs(long); shortAllocs != longAllocs {
+		t.Errorf("allocations depend on path length: %v vs %v", shortAllocs, longAllocs)
+	}
+}
+
+func BenchmarkWriteSvgPathDataTransformed(b *testing.B) {
+	transform := RotateAffine(1).Multiply(ScaleAffine(2, 3))
+	for _, n := range []int{10, 100, 1000} {
+		svg := repeatedPathData(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				WriteSvgPathDataTransformed(svg, transform, &countingPathProxy{})
+			}
+		})
+	}
+}
+
+func TestParseWithLeadingTransform(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"translate(10,10) M0,0", []string{"moveTo(10.0000, 10.0000)"}},
+		{"M1,2 L3,4", []string{"moveTo(1.0000, 2.0000)", "lineTo(3.0000, 4.0000)"}},
+		{" translate(10) scale(2, 3) M1,1 L2,2", []string{"moveTo(12.0000, 3.0000)", "lineTo(14.0000, 6.0000)"}},
+		{"rotate(90 5 5) M10,5", []string{"moveTo(5.0000, 10.0000)"}},
+	}
+	for _, test := range tests {
+		recorder := NewRecordingProxy()
+		if err := ParseWithLeadingTransform(test.input, recorder); err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if err := recorder.Diff(test.expected); err != nil {
+			t.Errorf("%q: %v", test.input, err)
+		}
+	}
+
+	var parseErr *ParseError
+	for input, offset := range map[string]int{
+		"skewX(10) M0,0":        0,
+		"translate(1,a) M0,0":   10,
+		"translate(1,1 M0,0":    9,
+		"translate(1,1) M0,0 L": 21,
+	} {
+		err := ParseWithLeadingTransform(input, NewRecordingProxy())
+		if !errors.As(err, &parseErr) || parseErr.Offset != offset {
+			t.Errorf("%q: expected a parse error at offset %d, got %v", input, offset, err)
+		}
+	}
+}
+
+func TestNormalizeProxy(t *testing.T) {
+	segments := mustParsePath(t, "M5,5 h10 v20 h-10 Z")
+	lo, hi := Bounds(segments)
+	recorder := NewRecordingProxy()
+	WriteSegmentsToPath(segments, NewNormalizeProxy(recorder, lo, hi))
+	expected := []string{
+		"moveTo(0.0000, 0.0000)",
+		"lineTo(0.5000, 0.0000)",
+		"lineTo(0.5000, 1.0000)",
+		"lineTo(0.0000, 1.0000)",
+		"close()",
+	}
+	if err := recorder.Diff(expected); err != nil {
+		t.Error(err)
+	}
+
+	recorder = NewRecordingProxy()
+	proxy := NewNormalizeProxy(recorder, PathOffset{3, 4}, PathOffset{3, 4})
+	proxy.LineTo(3, 4)
+	if err := recorder.Diff([]string{"lineTo(0.0000, 0.0000)"}); err != nil {
+		t.Error(err)
+	}
+}
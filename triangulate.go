@@ -0,0 +1,289 @@
+package pathparsing
+
+import (
+	"math"
+	"sort"
+)
+
+// Triangulate flattens the path and splits the area painted by the nonzero
+// or, if evenOdd is set, the even-odd fill rule into triangles by ear
+// clipping. Holes are joined to the contour surrounding them with a bridge
+// edge first, so every triangle lies inside the filled area. Subpaths are
+// assumed not to cross each other.
+func Triangulate(segments []PathSegmentData, evenOdd bool) [][3]PathOffset {
+	var contours []contour
+	for _, c := range flattenContours(segments, DefaultFlattenTolerance) {
+		if points := cleanPolygon(c.points); len(points) >= 3 {
+			contours = append(contours, contour{points: points, closed: true})
+		}
+	}
+
+	var outers, holes [][]PathOffset
+	for i, weight := range contourFillWeights(contours, evenOdd) {
+		points := contours[i].points
+		switch {
+		case weight > 0:
+			outers = append(outers, orientPolygon(points, true))
+		case weight < 0:
+			holes = append(holes, orientPolygon(points, false))
+		}
+	}
+
+	holesByOuter := make([][][]PathOffset, len(outers))
+	for _, hole := range holes {
+		best := -1
+		for i, outer := range outers {
+			if windingNumber(outer, hole[0]) == 0 {
+				continue
+			}
+			if best < 0 || math.Abs(polygonArea(outer)) < math.Abs(polygonArea(outers[best])) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			holesByOuter[best] = append(holesByOuter[best], hole)
+		}
+	}
+
+	var triangles [][3]PathOffset
+	for i, outer := range outers {
+		polygonHoles := holesByOuter[i]
+		sort.Slice(polygonHoles, func(a, b int) bool {
+			return polygonHoles[a][maxXIndex(polygonHoles[a])].Dx > polygonHoles[b][maxXIndex(polygonHoles[b])].Dx
+		})
+		polygon := outer
+		for _, hole := range polygonHoles {
+			polygon = bridgeHole(polygon, hole)
+		}
+		triangles = earClip(polygon, triangles)
+	}
+	return triangles
+}
+
+// cleanPolygon returns the points of a closed polygon without consecutive
+// repeated points, including a final point repeating the first.
+func cleanPolygon(points []PathOffset) []PathOffset {
+	var polygon []PathOffset
+	for i, p := range points {
+		if p != points[(i+1)%len(points)] {
+			polygon = append(polygon, p)
+		}
+	}
+	return polygon
+}
+
+// orientPolygon returns the polygon running with positive signed area if
+// positive is set, and with negative signed area otherwise.
+func orientPolygon(points []PathOffset, positive bool) []PathOffset {
+	if (polygonArea(points) > 0) == positive {
+		return points
+	}
+	reversed := make([]PathOffset, len(points))
+	for i, p := range points {
+		reversed[len(points)-1-i] = p
+	}
+	return reversed
+}
+
+// cross returns the z component of the cross product of b-a and c-b, which
+// is positive when a, b, c turn the same way as a polygon of positive area.
+func cross(a, b, c PathOffset) float64 {
+	return (b.Dx-a.Dx)*(c.Dy-b.Dy) - (b.Dy-a.Dy)*(c.Dx-b.Dx)
+}
+
+// pointInTriangle reports whether p lies inside or on the triangle a, b, c,
+// which must have positive orientation.
+func pointInTriangle(p, a, b, c PathOffset) bool {
+	return cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0
+}
+
+// maxXIndex returns the index of the rightmost point.
+func maxXIndex(points []PathOffset) int {
+	best := 0
+	for i, p := range points {
+		if p.Dx > points[best].Dx {
+			best = i
+		}
+	}
+	return best
+}
+
+// bridgeHole joins a hole of negative orientation to the polygon of positive
+// orientation surrounding it, returning a single polygon that runs around the
+// outside, along a bridge to the hole, around the hole and back. The bridge
+// starts at the hole's rightmost vertex and ends at a polygon vertex visible
+// from it, found by casting a ray towards +x.
+func bridgeHole(polygon, hole []PathOffset) []PathOffset {
+	holeIndex := maxXIndex(hole)
+	m := hole[holeIndex]
+
+	edge := -1
+	hitX := math.Inf(1)
+	for i, a := range polygon {
+		b := polygon[(i+1)%len(polygon)]
+		if (a.Dy <= m.Dy) == (b.Dy <= m.Dy) {
+			continue
+		}
+		x := a.Dx + (m.Dy-a.Dy)*(b.Dx-a.Dx)/(b.Dy-a.Dy)
+		if x >= m.Dx && x < hitX {
+			hitX = x
+			edge = i
+		}
+	}
+	if edge < 0 {
+		return polygon
+	}
+
+	bridge := edge
+	if next := (edge + 1) % len(polygon); polygon[next].Dx > polygon[edge].Dx {
+		bridge = next
+	}
+	hit := PathOffset{hitX, m.Dy}
+	if p := polygon[bridge]; p != hit {
+		a, b, c := m, hit, p
+		if cross(a, b, c) < 0 {
+			b, c = c, b
+		}
+		bestAngle := math.Inf(1)
+		bestDistance := math.Inf(1)
+		for i, v := range polygon {
+			if i == bridge || v == m || !pointInTriangle(v, a, b, c) {
+				continue
+			}
+			d := v.Subtract(m)
+			angle := math.Atan2(math.Abs(d.Dy), d.Dx)
+			distance := math.Hypot(d.Dx, d.Dy)
+			if angle < bestAngle || (angle == bestAngle && distance < bestDistance) {
+				bestAngle = angle
+				bestDistance = distance
+				bridge = i
+			}
+		}
+	}
+
+	result := make([]PathOffset, 0, len(polygon)+len(hole)+2)
+	result = append(result, polygon[:bridge+1]...)
+	result = append(result, hole[holeIndex:]...)
+	result = append(result, hole[:holeIndex+1]...)
+	result = append(result, polygon[bridge:]...)
+	return result
+}
+
+// earClip appends the triangulation of a simple polygon of positive
+// orientation to triangles.
+func earClip(polygon []PathOffset, triangles [][3]PathOffset) [][3]PathOffset {
+	indices := make([]int, len(polygon))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	for len(indices) > 3 {
+		n := len(indices)
+		ear := -1
+		flattest, flattestTurn := 0, math.Inf(1)
+		for k := 0; k < n && ear < 0; k++ {
+			a := polygon[indices[(k+n-1)%n]]
+			b := polygon[indices[k]]
+			c := polygon[indices[(k+1)%n]]
+			turn := cross(a, b, c)
+			if math.Abs(turn) < flattestTurn {
+				flattest, flattestTurn = k, math.Abs(turn)
+			}
+			if turn <= 0 {
+				continue
+			}
+			ear = k
+			for _, j := range indices {
+				p := polygon[j]
+				if p != a && p != b && p != c && pointInTriangle(p, a, b, c) {
+					ear = -1
+					break
+				}
+			}
+		}
+
+		if ear < 0 {
+			// No ear was found, which only happens with degenerate input;
+			// drop the flattest vertex to guarantee progress.
+			ear = flattest
+		} else {
+			triangles = append(triangles, [3]PathOffset{
+				polygon[indices[(ear+n-1)%n]],
+				polygon[indices[ear]],
+				polygon[indices[(ear+1)%n]],
+			})
+		}
+		indices = append(indices[:ear], indices[ear+1:]...)
+	}
+
+	if len(indices) == 3 {
+		a, b, c := polygon[indices[0]], polygon[indices[1]], polygon[indices[2]]
+		if cross(a, b, c) > 0 {
+			triangles = append(triangles, [3]PathOffset{a, b, c})
+		}
+	}
+	return triangles
+}
+
+// IsSimplePolygon reports whether the path is a simple polygon, as
+// Triangulate's ear clipping expects of each contour: a single subpath,
+// closed or ending within tol of its start, that encloses some area and
+// whose flattened edges neither cross nor touch. Edges that do not share a
+// vertex must stay more than tol apart, and adjacent edges must not fold
+// back onto each other.
+func IsSimplePolygon(segments []PathSegmentData, tol float64) bool {
+	contours := flattenContours(segments, DefaultFlattenTolerance)
+	if len(contours) != 1 {
+		return false
+	}
+	c := contours[0]
+	first, last := c.points[0], *c.last()
+	if !c.closed && math.Hypot(last.Dx-first.Dx, last.Dy-first.Dy) > tol {
+		return false
+	}
+	polygon := cleanPolygon(c.points)
+	// Arcs are decomposed in float32, so a closing point may miss the start
+	// by a rounding error.
+	if n := len(polygon); n > 1 {
+		if d := polygon[n-1].Subtract(polygon[0]); math.Hypot(d.Dx, d.Dy) <= math.Max(tol, 1e-6) {
+			polygon = polygon[:n-1]
+		}
+	}
+	n := len(polygon)
+	if n < 3 || polygonArea(polygon) == 0 {
+		return false
+	}
+
+	for i := 0; i < n; i++ {
+		a, b, next := polygon[i], polygon[(i+1)%n], polygon[(i+2)%n]
+		d0, d1 := b.Subtract(a), next.Subtract(b)
+		if d0.Dx*d1.Dy-d0.Dy*d1.Dx == 0 && d0.Dx*d1.Dx+d0.Dy*d1.Dy < 0 {
+			return false
+		}
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if segmentDistance(a, b, polygon[j], polygon[(j+1)%n]) <= tol {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// segmentDistance returns the shortest distance between the line segments
+// ab and cd, which is zero if they intersect.
+func segmentDistance(a, b, c, d PathOffset) float64 {
+	d1, d2 := cross(a, b, c), cross(a, b, d)
+	d3, d4 := cross(c, d, a), cross(c, d, b)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return 0
+	}
+	distance := math.Inf(1)
+	for _, pair := range [][3]PathOffset{{a, c, d}, {b, c, d}, {c, a, b}, {d, a, b}} {
+		q := closestOnSegment(pair[0], pair[1], pair[2])
+		distance = math.Min(distance, math.Hypot(pair[0].Dx-q.Dx, pair[0].Dy-q.Dy))
+	}
+	return distance
+}
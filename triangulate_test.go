@@ -0,0 +1,66 @@
+package pathparsing
+
+import (
+	"math"
+	"testing"
+)
+
+func trianglesArea(triangles [][3]PathOffset) float64 {
+	area := 0.0
+	for _, tri := range triangles {
+		area += math.Abs(polygonArea(tri[:]))
+	}
+	return area
+}
+
+func TestTriangulateSquare(t *testing.T) {
+	triangles := Triangulate(mustParsePath(t, "M0,0 H10 V10 H0 Z"), false)
+	if len(triangles) != 2 {
+		t.Fatalf("expected 2 triangles, got %v", triangles)
+	}
+	assertClose(t, "area", 100, trianglesArea(triangles), 1e-9)
+}
+
+func TestTriangulateWithHoles(t *testing.T) {
+	tests := []struct {
+		input   string
+		evenOdd bool
+		area    float64
+	}{
+		{"M0,0 H10 V10 H0 Z M3,3 H7 V7 H3 Z", true, 84},
+		{"M0,0 H10 V10 H0 Z M3,3 H7 V7 H3 Z", false, 100},
+		{"M0,0 H10 V10 H0 Z M3,3 V7 H7 V3 Z", false, 84},
+		{"M0,0 H20 V10 H0 Z M2,2 H6 V6 H2 Z M12,3 H16 V8 H12 Z", true, 200 - 16 - 20},
+		{"M0,0 L10,0 L10,10 L5,3 L0,10 Z", false, 65},
+	}
+	for _, test := range tests {
+		triangles := Triangulate(mustParsePath(t, test.input), test.evenOdd)
+		assertClose(t, test.input, test.area, trianglesArea(triangles), 1e-9)
+	}
+}
+
+func TestIsSimplePolygon(t *testing.T) {
+	tests := []struct {
+		input  string
+		simple bool
+	}{
+		{"M0,0 H10 L12,5 L5,10 L-2,5 Z", true},
+		{"M0,0 H10 V10 H0 V0", true},
+		{"M0,5 A5,5 0 0 1 10,5 A5,5 0 0 1 0,5 Z", true},
+		{"M0,0 L10,10 L10,0 L0,10 Z", false},
+		{"M0,0 H10 L5,5 L10,10 H0 L5,5 Z", false},
+		{"M0,0 H10 H5 V5 Z", false},
+		{"M0,0 H10 V10 H0", false},
+		{"M0,0 H10 V10 Z M20,0 H30 V10 Z", false},
+		{"M0,0 H10 Z", false},
+		{"M0,0 H10 V10 H5.005 V0.005 H4.995 V10 H0 Z", false},
+	}
+	for _, test := range tests {
+		if actual := IsSimplePolygon(mustParsePath(t, test.input), 0.01); actual != test.simple {
+			t.Errorf("%q: expected %v, got %v", test.input, test.simple, actual)
+		}
+	}
+	if !IsSimplePolygon(mustParsePath(t, "M0,0 H10 V10 H5.005 V0.005 H4.995 V10 H0 Z"), 0) {
+		t.Error("expected edges 0.005 apart to be simple with no tolerance")
+	}
+}
@@ -0,0 +1,107 @@
+package pathparsing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IssueKind classifies a problem reported by ValidateSvgPathData.
+type IssueKind int
+
+const (
+	// IssueSyntax is malformed path data. Validation resumes at the next
+	// command letter.
+	IssueSyntax IssueKind = iota
+	// IssueMissingMoveTo is path data that does not start with a moveTo.
+	// Validation continues as if it started at the origin.
+	IssueMissingMoveTo
+	// IssueImplicitCommand is a command repeated without its letter. This is
+	// valid SVG but some authoring tools want it spelled out.
+	IssueImplicitCommand
+	// IssueNumericOverflow is a number outside the representable range.
+	IssueNumericOverflow
+	// IssueDegenerateArc is an arc with a zero radius, drawn as a line, or
+	// with coincident endpoints, which is not drawn at all.
+	IssueDegenerateArc
+)
+
+// Issue is a problem found in SVG path data.
+type Issue struct {
+	// Offset is the byte offset in the path data where the problem was found.
+	Offset  int
+	Kind    IssueKind
+	Message string
+}
+
+// String returns a string representation of the Issue.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s at offset %d", i.Message, i.Offset)
+}
+
+// ValidateSvgPathData parses SVG path data leniently and reports every
+// problem found instead of stopping at the first one. After a malformed
+// segment parsing resumes at the next command letter. A nil result means the
+// path data is free of issues.
+func ValidateSvgPathData(svg string) []Issue {
+	var issues []Issue
+	parser := newSvgPathStringSource(svg)
+	normalizer := NewSvgPathNormalizer()
+	for parser.hasMoreData() {
+		offset := parser.idx
+		command := mapLetterToSegmentType(rune(parser.str[offset]))
+		if parser.previousCommand == SvgPathSegTypeUnknown {
+			if command != SvgPathSegTypeMoveToAbs && command != SvgPathSegTypeMoveToRel {
+				issues = append(issues, Issue{offset, IssueMissingMoveTo, "path data must start with a moveTo command"})
+				parser.previousCommand = SvgPathSegTypeMoveToAbs
+			}
+		} else if command == SvgPathSegTypeUnknown && parser.isNumberStart(rune(parser.str[offset])) && parser.previousCommand != SvgPathSegTypeClose {
+			issues = append(issues, Issue{offset, IssueImplicitCommand, "implicit command"})
+		}
+
+		startPoint := normalizer.currentPoint
+		seg, err := parser.parseSegment()
+		if err != nil {
+			issue := issueFromError(err, offset)
+			issues = append(issues, issue)
+			parser.skipToNextCommand(offset, issue.Offset)
+			continue
+		}
+
+		normSeg := normalizer.normalizeSegment(seg)
+		if normSeg.Command == SvgPathSegTypeArcToAbs {
+			if normSeg.Point1.Dx == 0 || normSeg.Point1.Dy == 0 {
+				issues = append(issues, Issue{offset, IssueDegenerateArc, "arc has a zero radius and is drawn as a line"})
+			} else if normSeg.TargetPoint == startPoint {
+				issues = append(issues, Issue{offset, IssueDegenerateArc, "arc endpoints coincide and the arc is not drawn"})
+			}
+		}
+	}
+	return issues
+}
+
+// issueFromError converts a parse error for the segment starting at offset
+// into an Issue.
+func issueFromError(err error, offset int) Issue {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return Issue{offset, IssueSyntax, err.Error()}
+	}
+	kind := IssueSyntax
+	if errors.Is(parseErr, ErrNumericOverflow) {
+		kind = IssueNumericOverflow
+	}
+	return Issue{parseErr.Offset, kind, parseErr.Msg}
+}
+
+// skipToNextCommand moves to the first command letter at or after the error
+// offset in the segment that started at offset, so parsing can resume after
+// an error.
+func (s *SvgPathStringSource) skipToNextCommand(offset, errOffset int) {
+	s.idx = errOffset
+	if s.idx <= offset {
+		s.idx = offset + 1
+	}
+	for s.hasMoreData() && mapLetterToSegmentType(rune(s.str[s.idx])) == SvgPathSegTypeUnknown {
+		s.idx++
+	}
+}
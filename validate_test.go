@@ -0,0 +1,45 @@
+package pathparsing
+
+import "testing"
+
+func TestValidateSvgPathData(t *testing.T) {
+	issues := ValidateSvgPathData("M0,0 L10,# A0,5 0 0 1 20,0 L1.L5,5")
+	expected := []Issue{
+		{9, IssueSyntax, "first character of a number must be one of [0-9+-.]"},
+		{11, IssueDegenerateArc, "arc has a zero radius and is drawn as a line"},
+		{30, IssueSyntax, "there must be at least one digit following the decimal point"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, issues)
+	}
+	for i := range expected {
+		if issues[i] != expected[i] {
+			t.Errorf("issue %d: expected %v, got %v", i, expected[i], issues[i])
+		}
+	}
+}
+
+func TestValidateSvgPathDataKinds(t *testing.T) {
+	tests := []struct {
+		input string
+		kinds []IssueKind
+	}{
+		{"M0,0 L10,10 Z", nil},
+		{"L10,10 A5,5 0 0 1 10,10", []IssueKind{IssueMissingMoveTo, IssueDegenerateArc}},
+		{"M0,0 L1,1 2,2", []IssueKind{IssueImplicitCommand}},
+		{"M0,0 L1e99,0", []IssueKind{IssueNumericOverflow}},
+		{"M0,0 X L1,1 #", []IssueKind{IssueSyntax, IssueSyntax}},
+	}
+	for _, test := range tests {
+		issues := ValidateSvgPathData(test.input)
+		if len(issues) != len(test.kinds) {
+			t.Errorf("%q: expected kinds %v, got %v", test.input, test.kinds, issues)
+			continue
+		}
+		for i, kind := range test.kinds {
+			if issues[i].Kind != kind {
+				t.Errorf("%q: issue %d: expected kind %v, got %v", test.input, i, kind, issues[i])
+			}
+		}
+	}
+}
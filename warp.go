@@ -0,0 +1,100 @@
+package pathparsing
+
+import (
+	"math"
+	"sort"
+)
+
+// guideEdge is an edge of a flattened guide path together with the arc
+// length at which it starts.
+type guideEdge struct {
+	a, b          PathOffset
+	start, length float64
+}
+
+// guideEdges flattens the guide into its non-degenerate edges, measuring
+// arc length as in SubPath.
+func guideEdges(guide []PathSegmentData) []guideEdge {
+	var edges []guideEdge
+	distance := 0.0
+	for _, c := range flattenContours(guide, DefaultFlattenTolerance) {
+		points := c.polyline()
+		for i := 1; i < len(points); i++ {
+			a, b := points[i-1], points[i]
+			if length := math.Hypot(b.Dx-a.Dx, b.Dy-a.Dy); length > 0 {
+				edges = append(edges, guideEdge{a, b, distance, length})
+				distance += length
+			}
+		}
+	}
+	return edges
+}
+
+// warpPoint maps p onto the guide: p.Dx becomes the arc length along the
+// guide and p.Dy the offset along its normal, which points to the right of
+// the direction of travel in SVG's y-down coordinate system. Positions
+// before the start or past the end follow the first or last edge.
+func warpPoint(edges []guideEdge, p PathOffset) PathOffset {
+	i := sort.Search(len(edges), func(i int) bool {
+		return edges[i].start+edges[i].length > p.Dx
+	})
+	if i == len(edges) {
+		i--
+	}
+	e := edges[i]
+	tangent := e.b.Subtract(e.a).Multiply(1 / e.length)
+	normal := PathOffset{-tangent.Dy, tangent.Dx}
+	return lerp(e.a, e.b, (p.Dx-e.start)/e.length).Add(normal.Multiply(p.Dy))
+}
+
+// WarpAlongPath bends the source path so that it follows the guide path,
+// as text on a path does: every source x coordinate becomes an arc length
+// along the flattened guide and every y coordinate an offset from it along
+// the guide's normal, so a source lying along the x axis is laid onto the
+// guide itself. The source is flattened and its edges are split wherever
+// the guide turns, so the result consists of absolute moveTo, lineTo and
+// close segments. A guide of zero length gives nil.
+func WarpAlongPath(source []PathSegmentData, guide []PathSegmentData) []PathSegmentData {
+	edges := guideEdges(guide)
+	if len(edges) == 0 {
+		return nil
+	}
+
+	var result []PathSegmentData
+	emit := func(command SvgPathSegType, p PathOffset) {
+		result = append(result, PathSegmentData{Command: command, TargetPoint: warpPoint(edges, p)})
+	}
+	for _, c := range flattenContours(source, DefaultFlattenTolerance) {
+		points := c.polyline()
+		emit(SvgPathSegTypeMoveToAbs, points[0])
+		for i := 1; i < len(points); i++ {
+			a, b := points[i-1], points[i]
+			for _, x := range guideTurnsBetween(edges, a.Dx, b.Dx) {
+				emit(SvgPathSegTypeLineToAbs, lerp(a, b, (x-a.Dx)/(b.Dx-a.Dx)))
+			}
+			emit(SvgPathSegTypeLineToAbs, b)
+		}
+		if c.closed {
+			result = append(result, PathSegmentData{Command: SvgPathSegTypeClose, TargetPoint: result[len(result)-1].TargetPoint})
+		}
+	}
+	return result
+}
+
+// guideTurnsBetween returns the arc lengths strictly between from and to at
+// which one guide edge ends and the next begins, ordered from from to to.
+func guideTurnsBetween(edges []guideEdge, from, to float64) []float64 {
+	lo, hi := math.Min(from, to), math.Max(from, to)
+	var turns []float64
+	for _, e := range edges[1:] {
+		if e.start > lo && e.start < hi {
+			turns = append(turns, e.start)
+		}
+	}
+	if from > to {
+		for i, j := 0, len(turns)-1; i < j; i, j = i+1, j-1 {
+			turns[i], turns[j] = turns[j], turns[i]
+		}
+	}
+	return turns
+}
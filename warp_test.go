@@ -0,0 +1,41 @@
+package pathparsing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWarpAlongPathStraightGuide(t *testing.T) {
+	warped := WarpAlongPath(mustParsePath(t, "M0,-1 H10 V1 H0 Z"), mustParsePath(t, "M5,5 H105"))
+	expected := "M5,4 L15,4 L15,6 L5,6 L5,4 Z"
+	if actual := SerializeSegments(warped); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestWarpAlongPathBends(t *testing.T) {
+	// A half circle of radius 10 around (10, 0), bulging upwards.
+	guide := mustParsePath(t, "M0,0 A10,10 0 0 1 20,0")
+	warped := WarpAlongPath(mustParsePath(t, "M0,-1 H10 V1 H0 Z"), guide)
+	if len(warped) < 6 {
+		t.Fatalf("expected the edges to be split along the guide, got %v", warped)
+	}
+
+	// The top edge lies outside the guide circle and the bottom edge inside.
+	for _, seg := range warped {
+		radius := math.Hypot(seg.TargetPoint.Dx-10, seg.TargetPoint.Dy)
+		if math.Abs(radius-11) > 0.05 && math.Abs(radius-9) > 0.05 {
+			t.Errorf("%v is not one unit from the guide, radius %v", seg.TargetPoint, radius)
+		}
+	}
+
+	// A point halfway along the source is a quarter of the guide away from
+	// its start.
+	mid := warpPoint(guideEdges(guide), PathOffset{10 * math.Pi / 2, 0})
+	assertClose(t, "mid x", 10, mid.Dx, 0.01)
+	assertClose(t, "mid y", -10, mid.Dy, 0.01)
+
+	first := warped[0].TargetPoint
+	assertClose(t, "start x", -1, first.Dx, 0.1)
+	assertClose(t, "start y", 0, first.Dy, 0.1)
+}